@@ -0,0 +1,130 @@
+package gmp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// An Alphabet is a set of digits for encoding Int values in a custom
+// base, such as base58. The base is the number of digits.
+type Alphabet struct {
+	digits string
+	values [256]byte // digit value of each byte, or noDigit
+}
+
+const noDigit = 0xff
+
+// Predefined alphabets.
+var (
+	// Base58Bitcoin is the base58 alphabet used by Bitcoin addresses.
+	Base58Bitcoin = mustAlphabet("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+
+	// Base32Crockford is Douglas Crockford's base32 alphabet. Decoding is
+	// case-insensitive and reads I and L as 1 and O as 0.
+	Base32Crockford = mustAlphabet("0123456789ABCDEFGHJKMNPQRSTVWXYZ").
+			alias("abcdefghjkmnpqrstvwxyz", "ABCDEFGHJKMNPQRSTVWXYZ").
+			alias("IiLlOo", "111100")
+)
+
+// NewAlphabet returns an Alphabet with the given digits, in order of
+// increasing value. There must be between 2 and MaxBase distinct digits,
+// and '-', which marks negative values, must not be one of them.
+func NewAlphabet(digits string) (*Alphabet, error) {
+	if len(digits) < 2 || len(digits) > MaxBase {
+		return nil, fmt.Errorf("gmp: alphabet has %d digits, not between 2 and %d", len(digits), MaxBase)
+	}
+	a := &Alphabet{digits: digits}
+	for i := range a.values {
+		a.values[i] = noDigit
+	}
+	for i := 0; i < len(digits); i++ {
+		c := digits[i]
+		if c == '-' {
+			return nil, errors.New("gmp: alphabet contains '-'")
+		}
+		if a.values[c] != noDigit {
+			return nil, fmt.Errorf("gmp: alphabet contains %q twice", c)
+		}
+		a.values[c] = byte(i)
+	}
+	return a, nil
+}
+
+func mustAlphabet(digits string) *Alphabet {
+	a, err := NewAlphabet(digits)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// alias makes a decode each byte in from like the corresponding digit in
+// to, and returns a.
+func (a *Alphabet) alias(from, to string) *Alphabet {
+	for i := 0; i < len(from); i++ {
+		a.values[from[i]] = a.values[to[i]]
+	}
+	return a
+}
+
+// Base returns the number of digits in a.
+func (a *Alphabet) Base() int {
+	return len(a.digits)
+}
+
+// Digits returns the digits of a, in order of increasing value.
+func (a *Alphabet) Digits() string {
+	return a.digits
+}
+
+// gmpDigits returns the digits StringBase uses in base.
+func gmpDigits(base int) string {
+	if base <= 36 {
+		return "0123456789abcdefghijklmnopqrstuvwxyz"[:base]
+	}
+	return "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"[:base]
+}
+
+// Encode returns x in the base of a, written with its digits and with a
+// leading '-' if x is negative. Zero is written as the first digit.
+//
+// Encode treats x as a number: unlike the base58 encoding of byte strings,
+// leading zero bytes of a value don't turn into leading zero digits.
+func (a *Alphabet) Encode(x *Int) string {
+	s, _ := x.StringBase(a.Base())
+	b := []byte(s)
+	std := gmpDigits(a.Base())
+	for i, c := range b {
+		if c != '-' {
+			b[i] = a.digits[strings.IndexByte(std, c)]
+		}
+	}
+	return string(b)
+}
+
+// Decode sets z to the value of s, written with the digits of a and
+// optionally a leading '-', and returns z. Decode returns an error if s is
+// empty or contains other bytes.
+func (a *Alphabet) Decode(z *Int, s string) (*Int, error) {
+	b := []byte(s)
+	digits := b
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	if len(digits) == 0 {
+		return nil, errors.New("gmp: no digits to decode")
+	}
+	std := gmpDigits(a.Base())
+	for i, c := range digits {
+		v := a.values[c]
+		if v == noDigit {
+			return nil, fmt.Errorf("gmp: invalid digit %q at offset %d", c, len(b)-len(digits)+i)
+		}
+		digits[i] = std[v]
+	}
+	if _, ok := z.SetString(string(b), a.Base()); !ok {
+		return nil, fmt.Errorf("gmp: cannot decode %q", s)
+	}
+	return z, nil
+}
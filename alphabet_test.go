@@ -0,0 +1,97 @@
+package gmp
+
+import (
+	"testing"
+)
+
+func TestAlphabet(t *testing.T) {
+	hello := new(Int).SetBytes([]byte("Hello World!"))
+	for _, test := range []struct {
+		a    *Alphabet
+		x    *Int
+		want string
+	}{
+		{Base58Bitcoin, hello, "2NEpo7TZRRrLZSi2U"},
+		{Base58Bitcoin, NewInt(0), "1"},
+		{Base58Bitcoin, NewInt(-57), "-z"},
+		{Base32Crockford, NewInt(1234), "16J"},
+		{Base32Crockford, NewInt(31), "Z"},
+	} {
+		if got := test.a.Encode(test.x); got != test.want {
+			t.Errorf("Encode(%s) = %s; want %s", test.x, got, test.want)
+		}
+		y, err := test.a.Decode(new(Int), test.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y.Cmp(test.x) != 0 {
+			t.Errorf("Decode(%s) = %s; want %s", test.want, y, test.x)
+		}
+	}
+
+	for _, s := range []string{"16j", "i6J", "L6j"} {
+		if x, err := Base32Crockford.Decode(new(Int), s); err != nil || x.Int64() != 1234 {
+			t.Errorf("Decode(%s) = %v, %v; want 1234", s, x, err)
+		}
+	}
+	// Leading zero digits that read like a "0x" or "0b" prefix.
+	if x, err := Base32Crockford.Decode(new(Int), "0B"); err != nil || x.Int64() != 11 {
+		t.Errorf("Decode(0B) = %v, %v; want 11", x, err)
+	}
+	if x, err := Base58Bitcoin.Decode(new(Int), "1e"); err != nil || x.Int64() != 37 {
+		t.Errorf("Decode(1e) = %v, %v; want 37", x, err)
+	}
+	for _, s := range []string{"", "-", "0", "2NE po", "1l"} {
+		if _, err := Base58Bitcoin.Decode(new(Int), s); err == nil {
+			t.Errorf("Decode(%q) succeeded", s)
+		}
+	}
+
+	for _, digits := range []string{"", "0", "0120", "01-", string(make([]byte, 63))} {
+		if _, err := NewAlphabet(digits); err == nil {
+			t.Errorf("NewAlphabet(%q) succeeded", digits)
+		}
+	}
+	a, err := NewAlphabet("ab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Encode(NewInt(6)); got != "bba" {
+		t.Errorf("Encode(6) = %s; want bba", got)
+	}
+}
+
+func TestBase62(t *testing.T) {
+	for _, test := range []struct {
+		x    int64
+		want string
+	}{
+		{35, "Z"},
+		{36, "a"},
+		{61, "z"},
+		{62*62 - 1, "zz"},
+		{-3817, "-zZ"},
+	} {
+		x := NewInt(test.x)
+		if got, err := x.StringBase(62); err != nil || got != test.want {
+			t.Errorf("StringBase(%d, 62) = %s, %v; want %s", test.x, got, err, test.want)
+		}
+		if y, ok := new(Int).SetString(test.want, 62); !ok || y.Cmp(x) != 0 {
+			t.Errorf("SetString(%s, 62) = %v, %v; want %d", test.want, y, ok, test.x)
+		}
+	}
+
+	if s, err := NewRat(-61, 36).StringBase(62); err != nil || s != "-z/a" {
+		t.Errorf("Rat StringBase(62) = %s, %v; want -z/a", s, err)
+	}
+	f := new(Float)
+	if err := f.SetString("z.V", 62); err != nil || f.Float64() != 61.5 {
+		t.Errorf("Float SetString(z.V, 62) = %v, %v; want 61.5", f.Float64(), err)
+	}
+	if s, err := f.StringBase(62, 0); err != nil || s != "z.V" {
+		t.Errorf("Float StringBase(62) = %s, %v; want z.V", s, err)
+	}
+	if _, err := NewInt(1).StringBase(63); err == nil {
+		t.Error("StringBase(63) succeeded")
+	}
+}
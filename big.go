@@ -0,0 +1,168 @@
+//go:build cgo
+
+package gmp
+
+/*
+#cgo LDFLAGS: -lgmp
+#include <gmp.h>
+*/
+import "C"
+
+import (
+	"math/big"
+	"runtime"
+	"unsafe"
+)
+
+// Conversions to and from math/big copy the limbs directly instead of
+// going through a decimal string. math/big stores the magnitude as a
+// little-endian slice of machine words, which is exactly mpz_import's
+// least significant word first, native endian layout.
+
+const wordSize = C.size_t(unsafe.Sizeof(big.Word(0)))
+
+// SetBig sets z to x and returns z.
+func (z *Int) SetBig(x *big.Int) *Int {
+	z.doinit()
+	words := x.Bits()
+	if len(words) == 0 {
+		C.mpz_set_ui(z.ptr, 0)
+		return z
+	}
+	C.mpz_import(z.ptr, C.size_t(len(words)), -1, wordSize, 0, 0,
+		unsafe.Pointer(&words[0]))
+	if x.Sign() < 0 {
+		C.mpz_neg(z.ptr, z.ptr)
+	}
+	return z
+}
+
+// Big returns z as a newly allocated *big.Int.
+func (z *Int) Big() *big.Int {
+	z.doinit()
+	x := new(big.Int)
+	sign := z.Sign()
+	if sign == 0 {
+		return x
+	}
+	bits := int(C.mpz_sizeinbase(z.ptr, 2))
+	words := make([]big.Word, (bits+int(wordSize)*8-1)/(int(wordSize)*8))
+	var n C.size_t
+	C.mpz_export(unsafe.Pointer(&words[0]), &n, -1, wordSize, 0, 0, z.ptr)
+	x.SetBits(words[:n])
+	if sign < 0 {
+		x.Neg(x)
+	}
+	runtime.KeepAlive(z)
+	return x
+}
+
+// SetBig sets q to x and returns q.
+func (q *Rat) SetBig(x *big.Rat) *Rat {
+	q.doinit()
+	// x is normalized with a positive denominator, so q is canonical too.
+	q.Num().SetBig(x.Num())
+	q.Denom().SetBig(x.Denom())
+	return q
+}
+
+// Big returns q as a newly allocated *big.Rat.
+func (q *Rat) Big() *big.Rat {
+	q.doinit()
+	return new(big.Rat).SetFrac(q.Num().Big(), q.Denom().Big())
+}
+
+// SetBig sets f to x and returns f. The precision of f is increased if
+// necessary so that x is represented exactly. SetBig panics if x is an
+// infinity since gmp floats are always finite.
+func (f *Float) SetBig(x *big.Float) *Float {
+	f.doinit()
+	if x.IsInf() {
+		panic("gmp: cannot convert infinite big.Float")
+	}
+	if prec := x.MinPrec(); f.GetPrec() < prec {
+		f.SetPrec(prec)
+	}
+	f.setExact(x)
+	return f
+}
+
+// setExact sets f to the finite x without changing the precision of f, and
+// reports whether f can hold x exactly. Besides the values of at most
+// GetPrec() bits, f can hold the values that have bits beyond GetPrec() in
+// an extra limb, like the results of mpf arithmetic. If f cannot hold x,
+// f is left unchanged.
+func (f *Float) setExact(x *big.Float) bool {
+	f.doinit()
+	if x.Sign() == 0 {
+		C.mpf_set_ui(&f.i[0], 0)
+		runtime.KeepAlive(f)
+		return true
+	}
+
+	// x has bits from 2**(exp-1) down to 2**(exp-prec). Scale it to an
+	// integer mant whose lowest limb holds the lowest bit, so that the
+	// scaling back is by whole limbs, which mpf does exactly.
+	const limbBits = C.GMP_LIMB_BITS
+	prec := int(x.MinPrec())
+	exp := x.MantExp(nil)
+	lo := exp - prec
+	if lo < 0 {
+		lo -= limbBits - 1
+	}
+	lo /= limbBits
+	hi := (exp + limbBits - 1) / limbBits
+	if exp < 0 {
+		hi = exp / limbBits
+	}
+	if hi-lo > int(f.i[0]._mp_prec)+1 {
+		runtime.KeepAlive(f)
+		return false
+	}
+	mant, _ := new(big.Float).SetMantExp(x, -lo*limbBits).Int(nil)
+
+	m := new(Int).SetBig(mant)
+	C.mpf_set_z(&f.i[0], m.ptr)
+	m.Clear()
+	if lo >= 0 {
+		C.mpf_mul_2exp(&f.i[0], &f.i[0], C.mp_bitcnt_t(lo*limbBits))
+	} else {
+		C.mpf_div_2exp(&f.i[0], &f.i[0], C.mp_bitcnt_t(-lo*limbBits))
+	}
+	runtime.KeepAlive(f)
+	return true
+}
+
+// Big returns f as a newly allocated *big.Float. The result has at least
+// the precision of f and represents f exactly.
+func (f *Float) Big() *big.Float {
+	f.doinit()
+	x := new(big.Float).SetPrec(f.GetPrec())
+
+	// f is 0.d[n-1]...d[0] × B**exp for limbs d of GMP_LIMB_BITS bits each.
+	size, exp := int(f.i[0]._mp_size), int(f.i[0]._mp_exp)
+	n := size
+	if n < 0 {
+		n = -n
+	}
+	if n == 0 {
+		return x
+	}
+	m := new(Int)
+	m.doinit()
+	C.mpz_import(m.ptr, C.size_t(n), -1, C.size_t(unsafe.Sizeof(C.mp_limb_t(0))), 0, 0,
+		unsafe.Pointer(f.i[0]._mp_d))
+	runtime.KeepAlive(f)
+	mant := m.Big()
+	m.Clear()
+
+	if prec := uint(n * C.GMP_LIMB_BITS); x.Prec() < prec {
+		x.SetPrec(prec)
+	}
+	x.SetInt(mant)
+	x.SetMantExp(x, (exp-n)*C.GMP_LIMB_BITS)
+	if size < 0 {
+		x.Neg(x)
+	}
+	return x
+}
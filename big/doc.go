@@ -0,0 +1,80 @@
+/*
+Package big is a drop-in replacement for math/big backed by gmp.
+
+It mirrors the exported API of math/big, so that switching a program from
+math/big to gmp is a matter of changing the import path:
+
+	import "github.com/jamesadney/gmp/big"
+
+The Int and Rat types are thin layers over the corresponding types of
+package gmp, and *Int and *Rat can be converted to their gmp counterparts
+for free:
+
+	x := big.NewInt(42)
+	y := (*gmp.Int)(x)
+
+Int and Rat follow the semantics of math/big exactly. Float does too, but
+it is a thin layer over math/big.Float instead: gmp's mpf functions
+truncate their results, sometimes to more than the requested precision,
+so they cannot provide the rounding modes and accuracy of math/big. Its
+conversions from and to Int and Rat take the types of this package.
+
+Formatting, scanning and encoding are implemented by converting to the
+math/big types, which is exact but allocates.
+*/
+package big
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/jamesadney/gmp"
+)
+
+// MaxBase is the largest number base accepted for string conversions.
+const MaxBase = 10 + ('z' - 'a' + 1) + ('Z' - 'A' + 1)
+
+// Exponent and precision limits.
+const (
+	MaxExp  = math.MaxInt32  // largest supported exponent
+	MinExp  = math.MinInt32  // smallest supported exponent
+	MaxPrec = math.MaxUint32 // largest (theoretically) supported precision; likely memory-limited
+)
+
+// A Word represents a single digit of a multi-precision unsigned integer.
+type Word = gmp.Word
+
+// Accuracy describes the rounding error produced by the most recent
+// operation that generated a Float value, relative to the exact value.
+type Accuracy = big.Accuracy
+
+// Constants describing the Accuracy of a Float.
+const (
+	Below = big.Below
+	Exact = big.Exact
+	Above = big.Above
+)
+
+// RoundingMode determines how a Float value is rounded to the desired
+// precision, and how the integer quotient is adjusted by Int.Divide.
+type RoundingMode = big.RoundingMode
+
+// These constants define supported rounding modes.
+const (
+	ToNearestEven = big.ToNearestEven // == IEEE 754-2008 roundTiesToEven
+	ToNearestAway = big.ToNearestAway // == IEEE 754-2008 roundTiesToAway
+	ToZero        = big.ToZero        // == IEEE 754-2008 roundTowardZero
+	AwayFromZero  = big.AwayFromZero  // no IEEE 754-2008 equivalent
+	ToNegativeInf = big.ToNegativeInf // == IEEE 754-2008 roundTowardNegative
+	ToPositiveInf = big.ToPositiveInf // == IEEE 754-2008 roundTowardPositive
+)
+
+// Rounding modes that determine how the integer quotient is adjusted in
+// an integer division. See Daan Leijen, “Division and Modulus for
+// Computer Scientists”, for details.
+const (
+	Trunc = ToZero        // T-division (same as Go division)
+	Floor = ToNegativeInf // F-division
+	Round = ToNearestEven // R-division
+	Ceil  = ToPositiveInf // C-division
+)
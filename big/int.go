@@ -0,0 +1,825 @@
+package big
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/jamesadney/gmp"
+)
+
+// An Int represents a signed multi-precision integer.
+// The zero value for an Int represents the value 0.
+//
+// Operations always take pointer arguments (*Int) rather than Int values,
+// and each unique Int value requires its own unique *Int pointer.
+// To "copy" an Int value, an existing (or newly allocated) Int must be set
+// to a new value using the Int.Set method; shallow copies of Ints are not
+// supported and panic once the copy is used.
+type Int gmp.Int
+
+func (x *Int) gmp() *gmp.Int { return (*gmp.Int)(x) }
+
+// NewInt allocates and returns a new Int set to x.
+func NewInt(x int64) *Int { return new(Int).SetInt64(x) }
+
+// Sign returns:
+//
+//	-1 if x <  0
+//	 0 if x == 0
+//	+1 if x >  0
+func (x *Int) Sign() int { return x.gmp().Sign() }
+
+// SetInt64 sets z to x and returns z.
+func (z *Int) SetInt64(x int64) *Int {
+	z.gmp().SetInt64(x)
+	return z
+}
+
+// SetUint64 sets z to x and returns z.
+func (z *Int) SetUint64(x uint64) *Int {
+	z.gmp().SetUint64(x)
+	return z
+}
+
+// Set sets z to x and returns z.
+func (z *Int) Set(x *Int) *Int {
+	if z != x {
+		z.gmp().Set(x.gmp())
+	}
+	return z
+}
+
+// Bits provides raw (unchecked but fast) access to x by returning its
+// absolute value as a little-endian Word slice. Unlike math/big, the
+// result is a copy of the limbs of x, which are owned by gmp, so x and the
+// result don't share the same underlying array.
+func (x *Int) Bits() []Word { return x.gmp().Bits() }
+
+// SetBits sets z to the value of abs, interpreted as a little-endian Word
+// slice, and returns z. Unlike math/big, abs is copied, so z and abs don't
+// share the same underlying array.
+func (z *Int) SetBits(abs []Word) *Int {
+	z.gmp().SetBits(abs)
+	return z
+}
+
+// Abs sets z to |x| (the absolute value of x) and returns z.
+func (z *Int) Abs(x *Int) *Int {
+	z.gmp().Abs(x.gmp())
+	return z
+}
+
+// Neg sets z to -x and returns z.
+func (z *Int) Neg(x *Int) *Int {
+	z.gmp().Neg(x.gmp())
+	return z
+}
+
+// Add sets z to the sum x+y and returns z.
+func (z *Int) Add(x, y *Int) *Int {
+	z.gmp().Add(x.gmp(), y.gmp())
+	return z
+}
+
+// Sub sets z to the difference x-y and returns z.
+func (z *Int) Sub(x, y *Int) *Int {
+	z.gmp().Sub(x.gmp(), y.gmp())
+	return z
+}
+
+// Mul sets z to the product x*y and returns z.
+func (z *Int) Mul(x, y *Int) *Int {
+	z.gmp().Mul(x.gmp(), y.gmp())
+	return z
+}
+
+// MulRange sets z to the product of all integers
+// in the range [a, b] inclusively and returns z.
+// If a > b (empty range), the result is 1.
+func (z *Int) MulRange(a, b int64) *Int {
+	z.gmp().MulRange(a, b)
+	return z
+}
+
+// Binomial sets z to the binomial coefficient C(n, k) and returns z.
+func (z *Int) Binomial(n, k int64) *Int {
+	if k > n || k < 0 {
+		return z.SetInt64(0)
+	}
+	// reduce the number of multiplications by reducing k
+	if k > n-k {
+		k = n - k // C(n, k) == C(n, n-k)
+	}
+	var a, b Int
+	a.MulRange(n-k+1, n)
+	b.MulRange(1, k)
+	z.Quo(&a, &b)
+	a.gmp().Clear()
+	b.gmp().Clear()
+	return z
+}
+
+// checkDivisor panics like an integer division by zero if y is 0.
+func checkDivisor(y *Int) {
+	if y.Sign() == 0 {
+		panic("division by zero")
+	}
+}
+
+// Quo sets z to the quotient x/y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Quo implements truncated division (like Go); see QuoRem for more details.
+func (z *Int) Quo(x, y *Int) *Int {
+	checkDivisor(y)
+	z.gmp().Quo(x.gmp(), y.gmp())
+	return z
+}
+
+// Rem sets z to the remainder x%y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Rem implements truncated modulus (like Go); see QuoRem for more details.
+func (z *Int) Rem(x, y *Int) *Int {
+	checkDivisor(y)
+	z.gmp().Rem(x.gmp(), y.gmp())
+	return z
+}
+
+// QuoRem sets z to the quotient x/y and r to the remainder x%y
+// and returns the pair (z, r) for y != 0.
+// If y == 0, a division-by-zero run-time panic occurs.
+//
+// QuoRem implements T-division and modulus (like Go):
+//
+//	q = x/y      with the result truncated to zero
+//	r = x - y*q
+//
+// (See Daan Leijen, “Division and Modulus for Computer Scientists”.)
+// See DivMod for Euclidean division and modulus (unlike Go).
+func (z *Int) QuoRem(x, y, r *Int) (*Int, *Int) {
+	checkDivisor(y)
+	z.gmp().QuoRem(x.gmp(), y.gmp(), r.gmp())
+	return z, r
+}
+
+// Div sets z to the quotient x/y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Div implements Euclidean division (unlike Go); see DivMod for more details.
+func (z *Int) Div(x, y *Int) *Int {
+	checkDivisor(y)
+	z.gmp().Div(x.gmp(), y.gmp())
+	return z
+}
+
+// Mod sets z to the modulus x%y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Mod implements Euclidean modulus (unlike Go); see DivMod for more details.
+func (z *Int) Mod(x, y *Int) *Int {
+	checkDivisor(y)
+	z.gmp().Mod(x.gmp(), y.gmp())
+	return z
+}
+
+// DivMod sets z to the quotient x div y and m to the modulus x mod y
+// and returns the pair (z, m) for y != 0.
+// If y == 0, a division-by-zero run-time panic occurs.
+//
+// DivMod implements Euclidean division and modulus (unlike Go):
+//
+//	q = x div y  such that
+//	m = x - y*q  with 0 <= m < |y|
+//
+// (See Raymond T. Boute, “The Euclidean definition of the functions
+// div and mod”. ACM Transactions on Programming Languages and
+// Systems (TOPLAS), 14(2):127-144, New York, NY, USA, 4/1992.
+// ACM press.)
+// See QuoRem for T-division and modulus (like Go).
+func (z *Int) DivMod(x, y, m *Int) (*Int, *Int) {
+	checkDivisor(y)
+	z.gmp().DivMod(x.gmp(), y.gmp(), m.gmp())
+	return z, m
+}
+
+// Divide computes the integer quotient q and remainder r such that
+//
+//	q = f(x/y)
+//	r = x - y*q
+//
+// where f is described by the rounding mode, which must be one of Trunc,
+// Floor, Round or Ceil. Divide sets z to q if z != nil, updates r if
+// r != nil, and returns the pair (z, r) if y != 0. If y == 0, a
+// division-by-zero run-time panic occurs.
+func (z *Int) Divide(x, y, r *Int, mode RoundingMode) (*Int, *Int) {
+	checkDivisor(y)
+	switch mode {
+	case Trunc, Floor, Round, Ceil:
+	default:
+		panic("unsupported rounding mode")
+	}
+
+	var q, rem, ay Int
+	ay.Abs(y)
+	neg := x.Sign() != y.Sign()
+	q.QuoRem(x, y, &rem)
+	if rem.Sign() != 0 {
+		// q is truncated towards zero; step one unit away from zero
+		// where the rounding mode asks for it.
+		away := false
+		switch mode {
+		case Floor:
+			away = neg
+		case Ceil:
+			away = !neg
+		case Round:
+			var twice Int
+			switch twice.Lsh(&rem, 1).CmpAbs(&ay) {
+			case 1:
+				away = true
+			case 0:
+				away = q.Bit(0) == 1
+			}
+		}
+		if away {
+			if neg {
+				q.Sub(&q, intOne)
+				rem.Add(&rem, y)
+			} else {
+				q.Add(&q, intOne)
+				rem.Sub(&rem, y)
+			}
+		}
+	}
+	if z != nil {
+		z.Set(&q)
+	}
+	if r != nil {
+		r.Set(&rem)
+	}
+	return z, r
+}
+
+var intOne = NewInt(1)
+
+// Cmp compares x and y and returns:
+//
+//	-1 if x <  y
+//	 0 if x == y
+//	+1 if x >  y
+func (x *Int) Cmp(y *Int) (r int) { return x.gmp().Cmp(y.gmp()) }
+
+// CmpAbs compares the absolute values of x and y and returns:
+//
+//	-1 if |x| <  |y|
+//	 0 if |x| == |y|
+//	+1 if |x| >  |y|
+func (x *Int) CmpAbs(y *Int) int {
+	a, b := x.Bits(), y.Bits()
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Int64 returns the int64 representation of x.
+// If x cannot be represented in an int64, the result is undefined.
+func (x *Int) Int64() int64 { return x.gmp().Int64() }
+
+// Uint64 returns the uint64 representation of x.
+// If x cannot be represented in a uint64, the result is undefined.
+func (x *Int) Uint64() uint64 { return x.gmp().Uint64() }
+
+// IsInt64 reports whether x can be represented as an int64.
+func (x *Int) IsInt64() bool {
+	n := x.BitLen()
+	// math.MinInt64 is the only 64 bit value that fits.
+	return n <= 63 || n == 64 && x.Sign() < 0 && x.TrailingZeroBits() == 63
+}
+
+// IsUint64 reports whether x can be represented as a uint64.
+func (x *Int) IsUint64() bool {
+	return x.Sign() >= 0 && x.BitLen() <= 64
+}
+
+// Float64 returns the float64 value nearest x,
+// and an indication of any rounding that occurred.
+func (x *Int) Float64() (float64, Accuracy) {
+	if n := x.BitLen(); n <= 53 {
+		f := float64(x.Int64())
+		return f, Exact
+	}
+	return new(big.Float).SetInt(x.gmp().Big()).Float64()
+}
+
+// SetString sets z to the value of s, interpreted in the given base,
+// and returns z and a boolean indicating success. The entire string
+// (not just a prefix) must be valid for success. If SetString fails,
+// the value of z is undefined but the returned value is nil.
+//
+// The base argument must be 0 or a value between 2 and MaxBase.
+// For base 0, the number prefix determines the actual base: A prefix of
+// “0b” or “0B” selects base 2, “0”, “0o” or “0O” selects base 8,
+// and “0x” or “0X” selects base 16. Otherwise, the selected base is 10
+// and no prefix is accepted.
+//
+// For bases <= 36, lower and upper case letters are considered the same:
+// The letters 'a' to 'z' and 'A' to 'Z' represent digit values 10 to 35.
+// For bases > 36, the upper case letters 'A' to 'Z' represent the digit
+// values 36 to 61.
+//
+// For base 0, an underscore character “_” may appear between a base
+// prefix and an adjacent digit, and between successive digits; such
+// underscores do not change the value of the number.
+// Incorrect placement of underscores is reported as an error if there
+// are no other errors. If base != 0, underscores are not recognized
+// and act like any other character that is not a valid digit.
+func (z *Int) SetString(s string, base int) (*Int, bool) {
+	if base > 36 && base <= MaxBase {
+		// gmp orders the letters the other way around above base 36.
+		x, ok := new(big.Int).SetString(s, base)
+		if !ok {
+			return nil, false
+		}
+		z.gmp().SetBig(x)
+		return z, true
+	}
+	digits, b, ok := scanInt(s, base)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := z.gmp().SetString(digits, b); !ok {
+		return nil, false
+	}
+	return z, true
+}
+
+// scanInt validates s according to the rules of SetString for bases up
+// to 36, and returns its optional sign and digits stripped of any base
+// prefix and underscores, together with the selected base.
+func scanInt(s string, base int) (string, int, bool) {
+	if base != 0 && (base < 2 || base > 36) {
+		return "", 0, false
+	}
+	sign := ""
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+
+	// Underscores are only accepted with base 0, after a prefix or
+	// between digits.
+	underscores := base == 0
+	prefix := false
+	digits := make([]byte, 0, len(s))
+	if base == 0 {
+		base = 10
+		if len(s) > 1 && s[0] == '0' {
+			prefix = true
+			switch s[1] {
+			case 'b', 'B':
+				base, s = 2, s[2:]
+			case 'o', 'O':
+				base, s = 8, s[2:]
+			case 'x', 'X':
+				base, s = 16, s[2:]
+			default:
+				// The leading "0" is a digit of the octal number.
+				base, s = 8, s[1:]
+				digits = append(digits, '0')
+			}
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' && underscores && (prefix || len(digits) > 0) {
+			// Underscores must be followed by a digit.
+			if i+1 == len(s) || s[i+1] == '_' {
+				return "", 0, false
+			}
+			continue
+		}
+		if digitVal(c) >= base {
+			return "", 0, false
+		}
+		digits = append(digits, c)
+	}
+	if len(digits) == 0 {
+		return "", 0, false
+	}
+	return sign + string(digits), base, true
+}
+
+// digitVal returns the value of the digit c for bases up to 36, or a value
+// larger than any base if c is not a digit.
+func digitVal(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'z':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return MaxBase + 1
+}
+
+// SetBytes interprets buf as the bytes of a big-endian unsigned
+// integer, sets z to that value, and returns z.
+func (z *Int) SetBytes(buf []byte) *Int {
+	z.gmp().SetBytes(buf)
+	return z
+}
+
+// Bytes returns the absolute value of x as a big-endian byte slice.
+//
+// To use a fixed length slice, or a preallocated one, use FillBytes.
+func (x *Int) Bytes() []byte { return x.gmp().Bytes() }
+
+// FillBytes sets buf to the absolute value of x, storing it as a
+// zero-extended big-endian byte slice, and returns buf.
+//
+// If the absolute value of x doesn't fit in buf, FillBytes will panic.
+func (x *Int) FillBytes(buf []byte) []byte { return x.gmp().FillBytes(buf) }
+
+// BitLen returns the length of the absolute value of x in bits.
+// The bit length of 0 is 0.
+func (x *Int) BitLen() int { return x.gmp().BitLen() }
+
+// TrailingZeroBits returns the number of consecutive least significant
+// zero bits of |x|.
+func (x *Int) TrailingZeroBits() uint {
+	for i, w := range x.Bits() {
+		if w != 0 {
+			n := uint(0)
+			for ; w&1 == 0; w >>= 1 {
+				n++
+			}
+			return uint(i)*wordBits + n
+		}
+	}
+	return 0
+}
+
+const wordBits = 32 << (^uint(0) >> 63)
+
+// Exp sets z = x**y mod |m| (i.e. the sign of m is ignored), and returns z.
+// If m == nil or m == 0, z = x**y unless y <= 0 then z = 1. If m != 0,
+// y < 0, and x and m are not relatively prime, z is unchanged and nil is
+// returned.
+func (z *Int) Exp(x, y, m *Int) *Int {
+	if m == nil || m.Sign() == 0 {
+		if y.Sign() <= 0 {
+			return z.SetInt64(1)
+		}
+		if !y.IsUint64() {
+			// Only |x| <= 1 has a representable result.
+			switch {
+			case x.CmpAbs(intOne) > 0:
+				panic("gmp/big: exponent too large")
+			case x.Sign() < 0 && y.Bit(0) == 0:
+				return z.SetInt64(1)
+			}
+			return z.Set(x)
+		}
+		z.gmp().Exp(x.gmp(), y.gmp(), nil)
+		return z
+	}
+
+	var mAbs Int
+	mAbs.Abs(m)
+	if y.Sign() < 0 {
+		// for y < 0: x**y mod m == (x**(-1))**|y| mod m
+		var inverse, yAbs Int
+		if inverse.ModInverse(x, &mAbs) == nil {
+			return nil
+		}
+		yAbs.Neg(y)
+		z.gmp().Exp(inverse.gmp(), yAbs.gmp(), mAbs.gmp())
+		return z
+	}
+	z.gmp().Exp(x.gmp(), y.gmp(), mAbs.gmp())
+	return z
+}
+
+// GCD sets z to the greatest common divisor of a and b and returns z.
+// If x or y are not nil, GCD sets their value such that z = a*x + b*y.
+//
+// a and b may be positive, zero or negative. (Before Go 1.14 both had
+// to be > 0.) Regardless of the signs of a and b, z is always >= 0.
+//
+// If a == b == 0, GCD sets z = x = y = 0.
+//
+// If a == 0 and b != 0, GCD sets z = |b|, x = 0, y = sign(b) * 1.
+//
+// If a != 0 and b == 0, GCD sets z = |a|, x = sign(a) * 1, y = 0.
+func (z *Int) GCD(x, y, a, b *Int) *Int {
+	signA, signB := a.Sign(), b.Sign()
+	if signA == 0 || signB == 0 {
+		if signA == 0 {
+			z.Abs(b)
+		} else {
+			z.Abs(a)
+		}
+		if x != nil {
+			x.SetInt64(int64(signA))
+		}
+		if y != nil {
+			if signA == 0 {
+				y.SetInt64(int64(signB))
+			} else {
+				y.SetInt64(0)
+			}
+		}
+		return z
+	}
+
+	// gmp.Int.GCD only accepts positive arguments: compute the cofactors
+	// for |a| and |b| and fix their signs afterwards.
+	var aAbs, bAbs Int
+	aAbs.Abs(a)
+	bAbs.Abs(b)
+	z.gmp().GCD(x.gmpOrNil(), y.gmpOrNil(), aAbs.gmp(), bAbs.gmp())
+	if x != nil && signA < 0 {
+		x.Neg(x)
+	}
+	if y != nil && signB < 0 {
+		y.Neg(y)
+	}
+	return z
+}
+
+func (x *Int) gmpOrNil() *gmp.Int {
+	if x == nil {
+		return nil
+	}
+	return x.gmp()
+}
+
+// Rand sets z to a pseudo-random number in [0, n) and returns z.
+//
+// As this uses the math/rand package, it must not be used for
+// security-sensitive work. Use crypto/rand.Int instead.
+func (z *Int) Rand(rnd *rand.Rand, n *Int) *Int {
+	z.gmp().SetBig(new(big.Int).Rand(rnd, n.gmp().Big()))
+	return z
+}
+
+// ModInverse sets z to the multiplicative inverse of g in the ring ℤ/nℤ
+// and returns z. If g and n are not relatively prime, g has no
+// multiplicative inverse in the ring ℤ/nℤ. In this case, z is unchanged
+// and the return value is nil. If n == 0, a division-by-zero run-time
+// panic occurs.
+func (z *Int) ModInverse(g, n *Int) *Int {
+	checkDivisor(n)
+	var nAbs, gMod, d, x Int
+	nAbs.Abs(n)
+	gMod.Mod(g, &nAbs)
+	d.GCD(&x, nil, &gMod, &nAbs)
+
+	// if and only if d==1, g and n are relatively prime
+	if d.Cmp(intOne) != 0 {
+		return nil
+	}
+
+	// x and y are such that g*x + n*y = 1, therefore x is the inverse
+	// element, but it may be negative, so convert to the range 0 <= z < |n|
+	if x.Sign() < 0 {
+		z.Add(&x, &nAbs)
+	} else {
+		z.Set(&x)
+	}
+	return z
+}
+
+// Jacobi returns the Jacobi symbol (x/y), either +1, -1, or 0. The y
+// argument must be an odd integer.
+func Jacobi(x, y *Int) int {
+	return big.Jacobi(x.gmp().Big(), y.gmp().Big())
+}
+
+// ModSqrt sets z to a square root of x mod p if such a square root exists,
+// and returns z. The modulus p must be an odd prime. If x is not a square
+// mod p, ModSqrt leaves z unchanged and returns nil. This function panics
+// if p is not an odd integer, its behavior is undefined if p is odd but
+// not prime.
+func (z *Int) ModSqrt(x, p *Int) *Int {
+	r := new(big.Int).ModSqrt(x.gmp().Big(), p.gmp().Big())
+	if r == nil {
+		return nil
+	}
+	z.gmp().SetBig(r)
+	return z
+}
+
+// Lsh sets z = x << n and returns z.
+func (z *Int) Lsh(x *Int, n uint) *Int {
+	z.gmp().Lsh(x.gmp(), n)
+	return z
+}
+
+// Rsh sets z = x >> n and returns z.
+func (z *Int) Rsh(x *Int, n uint) *Int {
+	z.gmp().Rsh(x.gmp(), n)
+	return z
+}
+
+// Bit returns the value of the i'th bit of x. That is, it
+// returns (x>>i)&1. The bit index i must be >= 0.
+func (x *Int) Bit(i int) uint {
+	if i < 0 {
+		panic("negative bit index")
+	}
+	return x.gmp().Bit(i)
+}
+
+// SetBit sets z to x, with x's i'th bit set to b (0 or 1).
+// That is, if b is 1 SetBit sets z = x | (1 << i);
+// if b is 0 SetBit sets z = x &^ (1 << i). If b is not 0 or 1,
+// SetBit will panic.
+func (z *Int) SetBit(x *Int, i int, b uint) *Int {
+	z.gmp().SetBit(x.gmp(), i, b)
+	return z
+}
+
+// And sets z = x & y and returns z.
+func (z *Int) And(x, y *Int) *Int {
+	z.gmp().And(x.gmp(), y.gmp())
+	return z
+}
+
+// AndNot sets z = x &^ y and returns z.
+func (z *Int) AndNot(x, y *Int) *Int {
+	z.gmp().AndNot(x.gmp(), y.gmp())
+	return z
+}
+
+// Or sets z = x | y and returns z.
+func (z *Int) Or(x, y *Int) *Int {
+	z.gmp().Or(x.gmp(), y.gmp())
+	return z
+}
+
+// Xor sets z = x ^ y and returns z.
+func (z *Int) Xor(x, y *Int) *Int {
+	z.gmp().Xor(x.gmp(), y.gmp())
+	return z
+}
+
+// Not sets z = ^x and returns z.
+func (z *Int) Not(x *Int) *Int {
+	z.gmp().Not(x.gmp())
+	return z
+}
+
+// Sqrt sets z to ⌊√x⌋, the largest integer such that z² ≤ x, and returns z.
+// It panics if x is negative.
+func (z *Int) Sqrt(x *Int) *Int {
+	if x.Sign() < 0 {
+		panic("square root of negative number")
+	}
+	z.gmp().Sqrt(x.gmp())
+	return z
+}
+
+// ProbablyPrime reports whether x is probably prime, applying
+// gmp's Baillie-PSW test followed by additional Miller-Rabin tests.
+// n is the number of Miller-Rabin rounds requested by the caller; see
+// math/big.Int.ProbablyPrime for the error probabilities.
+//
+// ProbablyPrime panics if n < 0.
+func (x *Int) ProbablyPrime(n int) bool {
+	if n < 0 {
+		panic("negative n for ProbablyPrime")
+	}
+	if x.Sign() <= 0 {
+		return false
+	}
+	return x.gmp().ProbablyPrime(n)
+}
+
+// Text returns the string representation of x in the given base.
+// Base must be between 2 and 62, inclusive. The result uses the
+// lower-case letters 'a' to 'z' for digit values 10 to 35, and
+// the upper-case letters 'A' to 'Z' for digit values 36 to 61.
+// No prefix (such as "0x") is added to the string. If x is a nil
+// pointer it returns "<nil>".
+func (x *Int) Text(base int) string {
+	if x == nil {
+		return "<nil>"
+	}
+	if base > 36 {
+		return x.gmp().Big().Text(base)
+	}
+	s, err := x.gmp().StringBase(base)
+	if err != nil {
+		panic("invalid base")
+	}
+	return s
+}
+
+// Append appends the string representation of x, as generated by
+// x.Text(base), to buf and returns the extended buffer.
+func (x *Int) Append(buf []byte, base int) []byte {
+	return append(buf, x.Text(base)...)
+}
+
+// String returns the decimal representation of x as generated by
+// x.Text(10).
+func (x *Int) String() string { return x.Text(10) }
+
+// Format implements fmt.Formatter. It accepts the same formats as
+// math/big.Int.Format.
+func (x *Int) Format(s fmt.State, ch rune) {
+	var b *big.Int
+	if x != nil {
+		b = x.gmp().Big()
+	}
+	b.Format(s, ch)
+}
+
+// Scan is a support routine for fmt.Scanner; it sets z to the value of
+// the scanned number. It accepts the formats 'b' (binary), 'o' (octal),
+// 'd' (decimal), 'x' (lowercase hexadecimal), and 'X' (uppercase
+// hexadecimal).
+func (z *Int) Scan(s fmt.ScanState, ch rune) error {
+	var x big.Int
+	if err := x.Scan(s, ch); err != nil {
+		return err
+	}
+	z.gmp().SetBig(&x)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (x *Int) GobEncode() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return x.gmp().Big().GobEncode()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *Int) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		// Other side sent a nil or default value.
+		z.SetInt64(0)
+		return nil
+	}
+	var x big.Int
+	if err := x.GobDecode(buf); err != nil {
+		return err
+	}
+	z.gmp().SetBig(&x)
+	return nil
+}
+
+// AppendText implements the encoding.TextAppender interface.
+func (x *Int) AppendText(b []byte) (text []byte, err error) {
+	return x.Append(b, 10), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x *Int) MarshalText() (text []byte, err error) {
+	if x == nil {
+		return []byte("<nil>"), nil
+	}
+	return x.AppendText(nil)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (z *Int) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text), 0); !ok {
+		return fmt.Errorf("math/big: cannot unmarshal %q into a *big.Int", text)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (x *Int) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	return x.AppendText(nil)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (z *Int) UnmarshalJSON(text []byte) error {
+	// Ignore null, like in the main JSON package.
+	if string(text) == "null" {
+		return nil
+	}
+	return z.UnmarshalText(text)
+}
@@ -0,0 +1,278 @@
+package big
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/jamesadney/gmp"
+)
+
+// A Rat represents a quotient a/b of arbitrary precision.
+// The zero value for a Rat represents the value 0.
+//
+// Operations always take pointer arguments (*Rat) rather than Rat values,
+// and each unique Rat value requires its own unique *Rat pointer.
+// To "copy" a Rat value, an existing (or newly allocated) Rat must be set
+// to a new value using the Rat.Set method; shallow copies of Rats are not
+// supported and panic once the copy is used.
+type Rat gmp.Rat
+
+func (x *Rat) gmp() *gmp.Rat { return (*gmp.Rat)(x) }
+
+// NewRat creates a new Rat with numerator a and denominator b.
+func NewRat(a, b int64) *Rat {
+	return new(Rat).SetFrac64(a, b)
+}
+
+// SetFloat64 sets z to exactly f and returns z.
+// If f is not finite, SetFloat returns nil.
+func (z *Rat) SetFloat64(f float64) *Rat {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	z.gmp().SetFloat64(f)
+	return z
+}
+
+// Float32 returns the nearest float32 value for x and a bool indicating
+// whether f represents x exactly. If the magnitude of x is too large to
+// be represented by a float32, f is an infinity and exact is false.
+// The sign of f always matches the sign of x, even if f == 0.
+func (x *Rat) Float32() (f float32, exact bool) {
+	return x.gmp().Big().Float32()
+}
+
+// Float64 returns the nearest float64 value for x and a bool indicating
+// whether f represents x exactly. If the magnitude of x is too large to
+// be represented by a float64, f is an infinity and exact is false.
+// The sign of f always matches the sign of x, even if f == 0.
+func (x *Rat) Float64() (f float64, exact bool) {
+	return x.gmp().Big().Float64()
+}
+
+// SetFrac sets z to a/b and returns z.
+// If b == 0, SetFrac panics.
+func (z *Rat) SetFrac(a, b *Int) *Rat {
+	if b.Sign() == 0 {
+		panic("division by zero")
+	}
+	z.gmp().SetFrac(a.gmp(), b.gmp())
+	return z
+}
+
+// SetFrac64 sets z to a/b and returns z.
+// If b == 0, SetFrac64 panics.
+func (z *Rat) SetFrac64(a, b int64) *Rat {
+	if b == 0 {
+		panic("division by zero")
+	}
+	if a == math.MinInt64 || b == math.MinInt64 {
+		// gmp.Rat.SetFrac64 negates its arguments.
+		return z.SetFrac(NewInt(a), NewInt(b))
+	}
+	z.gmp().SetFrac64(a, b)
+	return z
+}
+
+// SetInt sets z to x (by making a copy of x) and returns z.
+func (z *Rat) SetInt(x *Int) *Rat {
+	z.gmp().SetInt(x.gmp())
+	return z
+}
+
+// SetInt64 sets z to x and returns z.
+func (z *Rat) SetInt64(x int64) *Rat {
+	return z.SetFrac64(x, 1)
+}
+
+// SetUint64 sets z to x and returns z.
+func (z *Rat) SetUint64(x uint64) *Rat {
+	return z.SetInt(new(Int).SetUint64(x))
+}
+
+// Set sets z to x (by making a copy of x) and returns z.
+func (z *Rat) Set(x *Rat) *Rat {
+	if z != x {
+		z.gmp().Set(x.gmp())
+	}
+	return z
+}
+
+// Abs sets z to |x| (the absolute value of x) and returns z.
+func (z *Rat) Abs(x *Rat) *Rat {
+	z.gmp().Abs(x.gmp())
+	return z
+}
+
+// Neg sets z to -x and returns z.
+func (z *Rat) Neg(x *Rat) *Rat {
+	z.gmp().Neg(x.gmp())
+	return z
+}
+
+// Inv sets z to 1/x and returns z.
+// If x == 0, Inv panics.
+func (z *Rat) Inv(x *Rat) *Rat {
+	if x.Sign() == 0 {
+		panic("division by zero")
+	}
+	z.gmp().Inv(x.gmp())
+	return z
+}
+
+// Sign returns:
+//
+//	-1 if x <  0
+//	 0 if x == 0
+//	+1 if x >  0
+func (x *Rat) Sign() int { return x.gmp().Sign() }
+
+// IsInt reports whether the denominator of x is 1.
+func (x *Rat) IsInt() bool { return x.gmp().IsInt() }
+
+// Num returns the numerator of x; it may be <= 0.
+// The result is a reference to x's numerator; it
+// may change if a new value is assigned to x, and vice versa.
+// The sign of the numerator corresponds to the sign of x.
+func (x *Rat) Num() *Int { return (*Int)(x.gmp().Num()) }
+
+// Denom returns the denominator of x; it is always > 0.
+// The result is a reference to x's denominator; it
+// may change if a new value is assigned to x, and vice versa.
+func (x *Rat) Denom() *Int { return (*Int)(x.gmp().Denom()) }
+
+// Cmp compares x and y and returns:
+//
+//	-1 if x <  y
+//	 0 if x == y
+//	+1 if x >  y
+func (x *Rat) Cmp(y *Rat) int { return x.gmp().Cmp(y.gmp()) }
+
+// Add sets z to the sum x+y and returns z.
+func (z *Rat) Add(x, y *Rat) *Rat {
+	z.gmp().Add(x.gmp(), y.gmp())
+	return z
+}
+
+// Sub sets z to the difference x-y and returns z.
+func (z *Rat) Sub(x, y *Rat) *Rat {
+	z.gmp().Sub(x.gmp(), y.gmp())
+	return z
+}
+
+// Mul sets z to the product x*y and returns z.
+func (z *Rat) Mul(x, y *Rat) *Rat {
+	z.gmp().Mul(x.gmp(), y.gmp())
+	return z
+}
+
+// Quo sets z to the quotient x/y and returns z.
+// If y == 0, Quo panics.
+func (z *Rat) Quo(x, y *Rat) *Rat {
+	if y.Sign() == 0 {
+		panic("division by zero")
+	}
+	z.gmp().Quo(x.gmp(), y.gmp())
+	return z
+}
+
+// SetString sets z to the value of s and returns z and a boolean
+// indicating success. s can be given as a (possibly signed) fraction
+// "a/b", or as a floating-point number optionally followed by an
+// exponent, with the same syntax as math/big.Rat.SetString. If the
+// operation failed, the value of z is undefined but the returned value
+// is nil.
+func (z *Rat) SetString(s string) (*Rat, bool) {
+	x, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, false
+	}
+	z.gmp().SetBig(x)
+	return z, true
+}
+
+// String returns a string representation of x in the form "a/b" (even if
+// b == 1).
+func (x *Rat) String() string {
+	return x.Num().String() + "/" + x.Denom().String()
+}
+
+// RatString returns a string representation of x in the form "a/b" if
+// b != 1, and in the form "a" if b == 1.
+func (x *Rat) RatString() string {
+	if x.IsInt() {
+		return x.Num().String()
+	}
+	return x.String()
+}
+
+// FloatString returns a string representation of x in decimal form with
+// prec digits of precision after the radix point. The last digit is
+// rounded to nearest, with halves rounded away from zero.
+func (x *Rat) FloatString(prec int) string {
+	return x.gmp().Big().FloatString(prec)
+}
+
+// FloatPrec returns the number n of non-repeating digits immediately
+// following the decimal point of the decimal representation of x.
+// The boolean result indicates whether a decimal representation of x
+// with that many fractional digits is exact or rounded.
+func (x *Rat) FloatPrec() (n int, exact bool) {
+	return x.gmp().Big().FloatPrec()
+}
+
+// Scan is a support routine for fmt.Scanner. It accepts the formats
+// 'e', 'E', 'f', 'F', 'g', 'G', and 'v'. All formats are equivalent.
+func (z *Rat) Scan(s fmt.ScanState, ch rune) error {
+	var x big.Rat
+	if err := x.Scan(s, ch); err != nil {
+		return err
+	}
+	z.gmp().SetBig(&x)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (x *Rat) GobEncode() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return x.gmp().Big().GobEncode()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *Rat) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		// Other side sent a nil or default value.
+		z.SetInt64(0)
+		return nil
+	}
+	var x big.Rat
+	if err := x.GobDecode(buf); err != nil {
+		return err
+	}
+	z.gmp().SetBig(&x)
+	return nil
+}
+
+// AppendText implements the encoding.TextAppender interface.
+func (x *Rat) AppendText(b []byte) ([]byte, error) {
+	if x.IsInt() {
+		return x.Num().AppendText(b)
+	}
+	return append(b, x.String()...), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x *Rat) MarshalText() (text []byte, err error) {
+	return x.AppendText(nil)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (z *Rat) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text)); !ok {
+		return fmt.Errorf("math/big: cannot unmarshal %q into a *big.Rat", text)
+	}
+	return nil
+}
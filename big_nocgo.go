@@ -0,0 +1,70 @@
+//go:build !cgo
+
+package gmp
+
+import (
+	"math/big"
+)
+
+// Without cgo the values are math/big values already, so conversions are
+// plain copies.
+
+// SetBig sets z to x and returns z.
+func (z *Int) SetBig(x *big.Int) *Int {
+	z.doinit()
+	z.ptr.Set(x)
+	return z
+}
+
+// Big returns z as a newly allocated *big.Int.
+func (z *Int) Big() *big.Int {
+	z.doinit()
+	return new(big.Int).Set(z.ptr)
+}
+
+// SetBig sets q to x and returns q.
+func (q *Rat) SetBig(x *big.Rat) *Rat {
+	q.doinit()
+	q.i.Set(x)
+	return q
+}
+
+// Big returns q as a newly allocated *big.Rat.
+func (q *Rat) Big() *big.Rat {
+	q.doinit()
+	return new(big.Rat).Set(&q.i)
+}
+
+// SetBig sets f to x and returns f. The precision of f is increased if
+// necessary so that x is represented exactly. SetBig panics if x is an
+// infinity since gmp floats are always finite.
+func (f *Float) SetBig(x *big.Float) *Float {
+	f.doinit()
+	if x.IsInf() {
+		panic("gmp: cannot convert infinite big.Float")
+	}
+	if prec := x.MinPrec(); f.GetPrec() < prec {
+		f.SetPrec(prec)
+	}
+	f.i.Set(x)
+	return f
+}
+
+// setExact sets f to the finite x without changing the precision of f, and
+// reports whether f can hold x exactly, in the extra limb beyond GetPrec()
+// if needed. If it cannot, f is left unchanged.
+func (f *Float) setExact(x *big.Float) bool {
+	f.doinit()
+	if x.MinPrec() > f.i.Prec() {
+		return false
+	}
+	f.i.Set(x)
+	return true
+}
+
+// Big returns f as a newly allocated *big.Float. The result has at least
+// the precision of f and represents f exactly.
+func (f *Float) Big() *big.Float {
+	f.doinit()
+	return new(big.Float).SetPrec(f.i.Prec()).Set(&f.i)
+}
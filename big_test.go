@@ -0,0 +1,118 @@
+package gmp
+
+import (
+	"math/big"
+	"testing"
+)
+
+var bigIntTests = []string{
+	"0",
+	"1",
+	"-1",
+	"18446744073709551615",
+	"18446744073709551616",
+	"-18446744073709551616",
+	"340282366920938463463374607431768211457",
+	"-298472983472983471903246121093472394872319615612417471234712061",
+}
+
+func TestIntBig(t *testing.T) {
+	for i, s := range bigIntTests {
+		x, _ := new(big.Int).SetString(s, 10)
+		z := new(Int).SetBig(x)
+		if z.String() != s {
+			t.Errorf("#%d SetBig(%s) got %s", i, s, z)
+		}
+		if y := z.Big(); y.Cmp(x) != 0 {
+			t.Errorf("#%d Big() got %s want %s", i, y, s)
+		}
+	}
+}
+
+func TestRatBig(t *testing.T) {
+	for i, test := range ratBinTests {
+		x, _ := new(big.Rat).SetString(test.prod)
+		q := new(Rat).SetBig(x)
+		if q.RatString() != x.RatString() {
+			t.Errorf("#%d SetBig(%s) got %s", i, x, q)
+		}
+		if y := q.Big(); y.Cmp(x) != 0 {
+			t.Errorf("#%d Big() got %s want %s", i, y, x)
+		}
+	}
+}
+
+var bigFloatTests = []string{
+	"0",
+	"1",
+	"-0.5",
+	"0.1",
+	"1e100",
+	"-1.5e-100",
+	"123456789012345678901234567890.123456789",
+}
+
+func TestFloatBig(t *testing.T) {
+	for i, s := range bigFloatTests {
+		for _, prec := range []uint{24, 64, 200} {
+			x, _, _ := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+			f := new(Float).SetBig(x)
+			y := f.Big()
+			if y.Cmp(x) != 0 {
+				t.Errorf("#%d prec %d: round trip of %s got %s", i, prec, x.Text('p', 0), y.Text('p', 0))
+			}
+			if y.Prec() < x.MinPrec() {
+				t.Errorf("#%d prec %d: Big() has precision %d; want at least %d", i, prec, y.Prec(), x.MinPrec())
+			}
+		}
+	}
+}
+
+func TestFloatBigExact(t *testing.T) {
+	// 2**-1000 + 2**-1100 needs more bits than the default precision.
+	x := new(big.Float).SetPrec(200).SetMantExp(big.NewFloat(1), -1000)
+	x.Add(x, new(big.Float).SetMantExp(big.NewFloat(1), -1100))
+	f := new(Float).SetBig(x)
+	if y := f.Big(); y.Cmp(x) != 0 {
+		t.Errorf("got %s; want %s", y.Text('p', 0), x.Text('p', 0))
+	}
+}
+
+func benchmarkBigInt() *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(3), 10000)
+	return x.Sub(x, big.NewInt(1))
+}
+
+func BenchmarkIntSetBig(b *testing.B) {
+	x := benchmarkBigInt()
+	z := new(Int)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.SetBig(x)
+	}
+}
+
+func BenchmarkIntSetBigString(b *testing.B) {
+	x := benchmarkBigInt()
+	z := new(Int)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.SetString(x.String(), 10)
+	}
+}
+
+func BenchmarkIntBig(b *testing.B) {
+	z := new(Int).SetBig(benchmarkBigInt())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.Big()
+	}
+}
+
+func BenchmarkIntBigString(b *testing.B) {
+	z := new(Int).SetBig(benchmarkBigInt())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		new(big.Int).SetString(z.String(), 10)
+	}
+}
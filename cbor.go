@@ -0,0 +1,400 @@
+package gmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// CBOR (RFC 8949) major types and tags used by the CBOR encodings.
+const (
+	cborUint     = 0
+	cborNegInt   = 1
+	cborBytes    = 2
+	cborArray    = 4
+	cborTag      = 6
+	cborNull     = 0xf6 // simple value 22
+	tagPosBignum = 2
+	tagNegBignum = 3
+	tagDecimal   = 4
+	tagBigfloat  = 5
+	tagRational  = 30
+)
+
+var errCBORShort = errors.New("unexpected end of data")
+
+// appendCBORHead appends the head of a data item with major type major
+// and argument n, in its shortest form.
+func appendCBORHead(buf []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= 0xff:
+		return append(buf, major|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+}
+
+// appendCBORInt appends x as an integer if it fits into one and as a
+// bignum otherwise, which is the preferred serialization of RFC 8949.
+func appendCBORInt(buf []byte, x *Int) []byte {
+	major, tag := byte(cborUint), uint64(tagPosBignum)
+	n := x
+	if x.Sign() < 0 {
+		// Negative values are encoded as -1-n.
+		major, tag = cborNegInt, tagNegBignum
+		n = new(Int).Neg(x)
+		n.Sub(n, intOne)
+		defer n.Clear()
+	}
+	if n.BitLen() <= 64 {
+		return appendCBORHead(buf, major, n.Uint64())
+	}
+	mag := n.Bytes()
+	buf = appendCBORHead(buf, cborTag, tag)
+	buf = appendCBORHead(buf, cborBytes, uint64(len(mag)))
+	return append(buf, mag...)
+}
+
+// appendCBORFraction appends the decimal fraction or bigfloat m × b**e,
+// where tag selects the base b.
+func appendCBORFraction(buf []byte, tag uint64, m *Int, e int64) []byte {
+	buf = appendCBORHead(buf, cborTag, tag)
+	buf = appendCBORHead(buf, cborArray, 2)
+	if e < 0 {
+		buf = appendCBORHead(buf, cborNegInt, uint64(-1-e))
+	} else {
+		buf = appendCBORHead(buf, cborUint, uint64(e))
+	}
+	return appendCBORInt(buf, m)
+}
+
+// A cborDecoder reads CBOR data items from the front of data.
+type cborDecoder struct {
+	data []byte
+}
+
+// head reads the head of a data item. Indefinite lengths are not
+// supported.
+func (d *cborDecoder) head() (major byte, n uint64, err error) {
+	if len(d.data) == 0 {
+		return 0, 0, errCBORShort
+	}
+	major, info := d.data[0]>>5, d.data[0]&0x1f
+	d.data = d.data[1:]
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info > 27:
+		return 0, 0, fmt.Errorf("unsupported additional information %d", info)
+	}
+	size := 1 << (info - 24)
+	if len(d.data) < size {
+		return 0, 0, errCBORShort
+	}
+	for _, b := range d.data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	d.data = d.data[size:]
+	return major, n, nil
+}
+
+// int sets z to an integer or bignum read from d.
+func (d *cborDecoder) int(z *Int) error {
+	major, n, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cborUint:
+		z.SetUint64(n)
+		return nil
+	case cborNegInt:
+		z.SetUint64(n)
+	case cborTag:
+		if n != tagPosBignum && n != tagNegBignum {
+			return fmt.Errorf("unexpected tag %d", n)
+		}
+		neg := n == tagNegBignum
+		if major, n, err = d.head(); err != nil {
+			return err
+		}
+		if major != cborBytes {
+			return errors.New("bignum is not a byte string")
+		}
+		if n > uint64(len(d.data)) {
+			return errCBORShort
+		}
+		z.SetBytes(d.data[:n])
+		d.data = d.data[n:]
+		if !neg {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unexpected major type %d", major)
+	}
+	z.Add(z, intOne)
+	z.Neg(z)
+	return nil
+}
+
+// fraction reads the array of a decimal fraction or bigfloat, whose tag
+// has been read already, and returns its mantissa and exponent.
+func (d *cborDecoder) fraction() (m *Int, e int64, err error) {
+	major, n, err := d.head()
+	if err != nil {
+		return nil, 0, err
+	}
+	if major != cborArray || n != 2 {
+		return nil, 0, errors.New("fraction is not an array of two items")
+	}
+	major, n, err = d.head()
+	if err != nil {
+		return nil, 0, err
+	}
+	// Bound e like the binary exponents of Float.Parse.
+	if (major != cborUint && major != cborNegInt) || n > 1<<30 {
+		return nil, 0, errors.New("invalid exponent")
+	}
+	if e = int64(n); major == cborNegInt {
+		e = -1 - e
+	}
+	m = new(Int)
+	if err := d.int(m); err != nil {
+		m.Clear()
+		return nil, 0, err
+	}
+	return m, e, nil
+}
+
+// rat returns the exact value of a number read from d: an integer, a
+// bignum, a decimal fraction, a bigfloat or a rational.
+func (d *cborDecoder) rat() (*big.Rat, error) {
+	saved := d.data
+	major, tag, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case major != cborTag || tag == tagPosBignum || tag == tagNegBignum:
+		d.data = saved
+		x := new(Int)
+		defer x.Clear()
+		if err := d.int(x); err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt(x.Big()), nil
+	case tag == tagDecimal || tag == tagBigfloat:
+		m, e, err := d.fraction()
+		if err != nil {
+			return nil, err
+		}
+		defer m.Clear()
+		// Bound e like Float.Parse does, since the power is computed.
+		if e < -maxParseExp || e > maxParseExp {
+			return nil, errors.New("exponent out of range")
+		}
+		r := new(big.Rat).SetInt(m.Big())
+		if tag == tagDecimal {
+			return r.Mul(r, pow10(int(e))), nil
+		}
+		p := new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(abs(int(e)))))
+		if e < 0 {
+			return r.Quo(r, p), nil
+		}
+		return r.Mul(r, p), nil
+	case tag == tagRational:
+		major, n, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != cborArray || n != 2 {
+			return nil, errors.New("rational is not an array of two items")
+		}
+		num, denom := new(Int), new(Int)
+		defer num.Clear()
+		defer denom.Clear()
+		if err := d.int(num); err != nil {
+			return nil, err
+		}
+		if err := d.int(denom); err != nil {
+			return nil, err
+		}
+		if denom.Sign() <= 0 {
+			return nil, errors.New("denominator is not positive")
+		}
+		return new(big.Rat).SetFrac(num.Big(), denom.Big()), nil
+	}
+	return nil, fmt.Errorf("unexpected tag %d", tag)
+}
+
+// float returns the value of a number read from d, as a big.Float f if it
+// is a bigfloat or integral, and as a big.Rat r otherwise.
+func (d *cborDecoder) float() (f *big.Float, r *big.Rat, err error) {
+	saved := d.data
+	if major, tag, err := d.head(); err == nil && major == cborTag && tag == tagBigfloat {
+		// Unlike rat, don't compute the power of 2.
+		m, e, err := d.fraction()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer m.Clear()
+		f := new(big.Float).SetInt(m.Big())
+		return f.SetMantExp(f, int(e)), nil, nil
+	}
+	d.data = saved
+	r, err = d.rat()
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.IsInt() {
+		return new(big.Float).SetInt(r.Num()), nil, nil
+	}
+	return nil, r, nil
+}
+
+// isCBORNull reports whether data is the CBOR null.
+func isCBORNull(data []byte) bool {
+	return len(data) == 1 && data[0] == cborNull
+}
+
+// end returns an error if data is left after the decoded item.
+func (d *cborDecoder) end() error {
+	if len(d.data) > 0 {
+		return errors.New("trailing data")
+	}
+	return nil
+}
+
+// MarshalCBOR returns the CBOR encoding of x: an integer if x fits into
+// 64 bits, and an RFC 8949 bignum (tag 2 or 3) otherwise. A nil x is
+// encoded as null.
+func (x *Int) MarshalCBOR() ([]byte, error) {
+	if x == nil {
+		return []byte{cborNull}, nil
+	}
+	return appendCBORInt(nil, x), nil
+}
+
+// UnmarshalCBOR sets z to the value of a CBOR integer or bignum. null
+// leaves z unchanged.
+func (z *Int) UnmarshalCBOR(data []byte) error {
+	if isCBORNull(data) {
+		return nil
+	}
+	d := cborDecoder{data}
+	err := d.int(z)
+	if err == nil {
+		err = d.end()
+	}
+	if err != nil {
+		return fmt.Errorf("gmp: Int.UnmarshalCBOR: %v", err)
+	}
+	return nil
+}
+
+// MarshalCBOR returns the CBOR encoding of x as a rational number (tag
+// 30): an array of the numerator and the denominator, each an integer or a
+// bignum. A nil x is encoded as null.
+func (x *Rat) MarshalCBOR() ([]byte, error) {
+	if x == nil {
+		return []byte{cborNull}, nil
+	}
+	buf := appendCBORHead(nil, cborTag, tagRational)
+	buf = appendCBORHead(buf, cborArray, 2)
+	buf = appendCBORInt(buf, x.Num())
+	return appendCBORInt(buf, x.Denom()), nil
+}
+
+// UnmarshalCBOR sets z to the value of a CBOR rational number, integer,
+// bignum, decimal fraction or bigfloat, all of which z represents exactly.
+// null leaves z unchanged.
+func (z *Rat) UnmarshalCBOR(data []byte) error {
+	if isCBORNull(data) {
+		return nil
+	}
+	d := cborDecoder{data}
+	r, err := d.rat()
+	if err == nil {
+		err = d.end()
+	}
+	if err != nil {
+		return fmt.Errorf("gmp: Rat.UnmarshalCBOR: %v", err)
+	}
+	z.SetBig(r)
+	return nil
+}
+
+// MarshalCBOR returns the CBOR encoding of x as a bigfloat (tag 5): an
+// array of an exponent e and an integer or bignum mantissa m with
+// x = m × 2**e exactly. A nil x is encoded as null.
+func (x *Float) MarshalCBOR() ([]byte, error) {
+	if x == nil {
+		return []byte{cborNull}, nil
+	}
+	mant, exp := x.oddMantExp()
+	m := new(Int).SetBig(mant)
+	defer m.Clear()
+	return appendCBORFraction(nil, tagBigfloat, m, int64(exp)), nil
+}
+
+// MarshalCBORDecimal returns the CBOR encoding of x as a decimal fraction
+// (tag 4): an array of an exponent e and an integer or bignum mantissa m
+// with x = m × 10**e exactly. A nil x is encoded as null.
+func (x *Float) MarshalCBORDecimal() ([]byte, error) {
+	if x == nil {
+		return []byte{cborNull}, nil
+	}
+	mant, exp := x.oddMantExp()
+	if exp < 0 {
+		// m × 2**e = m × 5**-e × 10**e
+		p := new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(-exp)), nil)
+		mant.Mul(mant, p)
+	} else if mant.Sign() != 0 {
+		mant.Lsh(mant, uint(exp))
+		exp = 0
+		ten := big.NewInt(10)
+		for {
+			q, r := new(big.Int).QuoRem(mant, ten, new(big.Int))
+			if r.Sign() != 0 {
+				break
+			}
+			mant = q
+			exp++
+		}
+	}
+	m := new(Int).SetBig(mant)
+	defer m.Clear()
+	return appendCBORFraction(nil, tagDecimal, m, int64(exp)), nil
+}
+
+// UnmarshalCBOR sets z to the value of a CBOR bigfloat, decimal fraction,
+// rational number, integer or bignum. Bigfloats and integral values are
+// set exactly, and the precision of z grows if needed like with SetBig.
+// Other values are truncated to the precision of z like with
+// UnmarshalText, unless z can hold them exactly. null leaves z unchanged.
+func (z *Float) UnmarshalCBOR(data []byte) error {
+	if isCBORNull(data) {
+		return nil
+	}
+	d := cborDecoder{data}
+	f, r, err := d.float()
+	if err == nil {
+		err = d.end()
+	}
+	if err != nil {
+		return fmt.Errorf("gmp: Float.UnmarshalCBOR: %v", err)
+	}
+	if r != nil {
+		z.setTruncated(r)
+	} else if !z.setExact(f) {
+		z.SetBig(f)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package gmp
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// setMinimalSignedBytes sets z to the value of the ASN.1 INTEGER contents b,
+// which must be minimal unlike for SetSignedBytes, and returns z.
+func setMinimalSignedBytes(z *Int, b []byte) (*Int, error) {
+	switch {
+	case len(b) == 0:
+		return nil, errors.New("empty integer")
+	case len(b) > 1 && (b[0] == 0 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0):
+		return nil, errors.New("integer not minimally encoded")
+	}
+	return z.SetSignedBytes(b), nil
+}
+
+// MarshalDER returns the DER encoding of x as an ASN.1 INTEGER: the
+// universal tag 2, the length and the contents x.SignedBytes().
+func (x *Int) MarshalDER() ([]byte, error) {
+	b := x.SignedBytes()
+	buf := []byte{asn1.TagInteger}
+	if len(b) < 0x80 {
+		buf = append(buf, byte(len(b)))
+	} else {
+		var n []byte
+		for l := len(b); l > 0; l >>= 8 {
+			n = append([]byte{byte(l)}, n...)
+		}
+		buf = append(buf, 0x80|byte(len(n)))
+		buf = append(buf, n...)
+	}
+	return append(buf, b...), nil
+}
+
+// UnmarshalDER sets z to the value of the DER encoded ASN.1 INTEGER data.
+// It rejects other BER encodings of the value: the length and the contents
+// must be minimal.
+func (z *Int) UnmarshalDER(data []byte) error {
+	b, err := parseDERInteger(data)
+	if err == nil {
+		_, err = setMinimalSignedBytes(z, b)
+	}
+	if err != nil {
+		return fmt.Errorf("gmp: Int.UnmarshalDER: %v", err)
+	}
+	return nil
+}
+
+// parseDERInteger returns the contents of the DER encoded INTEGER data.
+func parseDERInteger(data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, errors.New("data truncated")
+	}
+	if data[0] != asn1.TagInteger {
+		return nil, fmt.Errorf("tag %#x is not INTEGER", data[0])
+	}
+	n, data := int(data[1]), data[2:]
+	if n&0x80 != 0 {
+		size := n &^ 0x80
+		switch {
+		case size == 0:
+			return nil, errors.New("indefinite length")
+		case size > 4 || size > len(data):
+			return nil, errors.New("length too large")
+		case data[0] == 0:
+			return nil, errors.New("length not minimally encoded")
+		}
+		n = 0
+		for _, c := range data[:size] {
+			n = n<<8 | int(c)
+		}
+		if n < 0 {
+			return nil, errors.New("length too large")
+		}
+		if n < 0x80 {
+			return nil, errors.New("length not minimally encoded")
+		}
+		data = data[size:]
+	}
+	switch {
+	case n > len(data):
+		return nil, errors.New("data truncated")
+	case n < len(data):
+		return nil, errors.New("trailing data")
+	}
+	return data, nil
+}
+
+// RawValue returns x as an ASN.1 INTEGER for use with encoding/asn1, which
+// marshals a RawValue field as is.
+func (x *Int) RawValue() asn1.RawValue {
+	return asn1.RawValue{
+		Class: asn1.ClassUniversal,
+		Tag:   asn1.TagInteger,
+		Bytes: x.SignedBytes(),
+	}
+}
+
+// SetRawValue sets z to the value of v, an ASN.1 INTEGER as unmarshaled
+// into a RawValue field by encoding/asn1, and returns z. The contents must
+// be minimal.
+func (z *Int) SetRawValue(v asn1.RawValue) (*Int, error) {
+	if v.Class != asn1.ClassUniversal || v.Tag != asn1.TagInteger || v.IsCompound {
+		return nil, fmt.Errorf("gmp: Int.SetRawValue: class %d tag %d is not INTEGER", v.Class, v.Tag)
+	}
+	if _, err := setMinimalSignedBytes(z, v.Bytes); err != nil {
+		return nil, fmt.Errorf("gmp: Int.SetRawValue: %v", err)
+	}
+	return z, nil
+}
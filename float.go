@@ -1,3 +1,5 @@
+//go:build cgo
+
 // Copyright 2010 Utkan Güngördü.
 // Based on $(GOROOT)/misc/cgo/gmp/gmp.go
 // Released under the BSD-style license that can
@@ -13,19 +15,48 @@ package gmp
 #cgo LDFLAGS: -lgmp
 #include <gmp.h>
 #include <stdlib.h>
+
+// The mpf struct is allocated with gmp's allocation function, see _mpz_new.
+// A precision of 0 selects the default precision.
+mpf_ptr _mpf_new(mp_bitcnt_t prec) {
+	void *(*alloc)(size_t);
+	mp_get_memory_functions(&alloc, NULL, NULL);
+	mpf_ptr f = alloc(sizeof(__mpf_struct));
+	if (prec != 0) {
+		mpf_init2(f, prec);
+	} else {
+		mpf_init(f);
+	}
+	return f;
+}
+void _mpf_delete(mpf_ptr f) {
+	void (*dealloc)(void *, size_t);
+	mp_get_memory_functions(NULL, NULL, &dealloc);
+	mpf_clear(f);
+	dealloc(f, sizeof(__mpf_struct));
+}
 */
 import "C"
 
 import (
 	"os"
+	"runtime"
 	"strconv"
 	"unsafe"
 )
 
+// A Float represents a multi-precision floating point number.
+// The zero value for a Float represents the value 0.
+//
+// A Float must not be copied by value once it has been used; doing so
+// panics. Pass *Float instead.
 type Float struct {
-	i    C.mpf_t
-	init bool
-	prec uint // 0 = use the default precision
+	noCopy  noCopy
+	addr    *Float   // of receiver, to detect copies by value
+	i       *C.mpf_t // in C memory, see Int.doinit
+	cleanup runtime.Cleanup
+	init    bool
+	prec    uint // 0 = use the default precision
 }
 
 // NewInt returns a new Int initialized to x.
@@ -42,25 +73,44 @@ func NewFloat2(x float64, prec uint) *Float {
 // Int promises that the zero value is a 0, but in gmp
 // the zero value is a crash.  To bridge the gap, the
 // init bool says whether this is a valid gmp value.
-// doinit initializes f.i if it needs it.  This is not inherent
-// to FFI, just a mismatch between Go's convention of
+// doinit allocates and initializes f.i if it needs it.  This is not
+// inherent to FFI, just a mismatch between Go's convention of
 // making zero values useful and gmp's decision not to.
+//
+// doinit also registers a cleanup so the mantissa is released once f
+// becomes unreachable, even if Clear is never called.
 func (f *Float) doinit() {
 	if f.init {
+		f.copyCheck()
 		return
 	}
-	if f.prec != 0 {
-		C.mpf_init2(&f.i[0], C.mp_bitcnt_t(f.prec))
-	} else {
-		C.mpf_init(&f.i[0])
-	}
+	f.i = (*C.mpf_t)(unsafe.Pointer(C._mpf_new(C.mp_bitcnt_t(f.prec))))
 	f.init = true
+	f.addr = f
+	trackInit(unsafe.Pointer(f.i), "Float")
+	f.cleanup = runtime.AddCleanup(f, clearFloat, f.i)
+	gcPressure()
+}
+
+// clearFloat frees the mpf p allocated by doinit.
+func clearFloat(p *C.mpf_t) {
+	trackClear(unsafe.Pointer(p))
+	C._mpf_delete(&p[0])
+}
+
+// copyCheck panics if f is an initialized Float that was copied by value.
+func (f *Float) copyCheck() {
+	if f.addr != f {
+		panic("gmp: illegal use of non-zero Float copied by value")
+	}
 }
 
 // Set sets f = x and returns f.
 func (f *Float) Set(x *Float) *Float {
+	x.doinit()
 	f.doinit()
 	C.mpf_set(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -71,6 +121,31 @@ func (f *Float) SetInt64(x int64) *Float {
 	return f
 }
 
+// SetUint64 sets f = x and returns f.
+func (f *Float) SetUint64(x uint64) *Float {
+	f.doinit()
+	C.mpf_set_ui(&f.i[0], C.ulong(x))
+	return f
+}
+
+// SetInt sets f = x, truncated to the precision of f, and returns f.
+func (f *Float) SetInt(x *Int) *Float {
+	x.doinit()
+	f.doinit()
+	C.mpf_set_z(&f.i[0], x.ptr)
+	runtime.KeepAlive(x)
+	return f
+}
+
+// SetRat sets f = x, truncated to the precision of f, and returns f.
+func (f *Float) SetRat(x *Rat) *Float {
+	x.doinit()
+	f.doinit()
+	C.mpf_set_q(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
+	return f
+}
+
 // SetFloat64 sets f = x and returns f.
 func (f *Float) SetFloat64(x float64) *Float {
 	f.doinit()
@@ -79,18 +154,20 @@ func (f *Float) SetFloat64(x float64) *Float {
 }
 
 // SetString interprets s as a number in the given base
-// and sets f to that value.  The base must be in the range [2,36].
+// and sets f to that value.  The base must be in the range [2,MaxBase].
 // SetString returns an error if s cannot be parsed or the base is invalid.
 func (f *Float) SetString(s string, base int) error {
 	f.doinit()
-	if base < 2 || base > 36 {
+	if base < 2 || base > MaxBase {
 		return os.ErrInvalid
 	}
 	p := C.CString(s)
 	defer C.free(unsafe.Pointer(p))
 	if C.mpf_set_str(&f.i[0], p, C.int(base)) < 0 {
+		runtime.KeepAlive(f)
 		return os.ErrInvalid
 	}
+	runtime.KeepAlive(f)
 	return nil
 }
 
@@ -98,15 +175,16 @@ func (f *Float) StringBase(base int, ndigits uint) (string, error) {
 	if f == nil {
 		return "nil", nil
 	}
-	if base < 2 || base > 36 {
+	if base < 2 || base > MaxBase {
 		return "", os.ErrInvalid
 	}
 	f.doinit()
 	var exp_ C.mp_exp_t
 	p := C.mpf_get_str(nil, &exp_, C.int(base), C.size_t(ndigits), &f.i[0])
+	runtime.KeepAlive(f)
 	exp := int(exp_)
 	s := C.GoString(p)
-	C.free(unsafe.Pointer(p))
+	freeString(p)
 
 	if len(s) == 0 {
 		return "0", nil
@@ -126,12 +204,16 @@ func (f *Float) String() string {
 
 func (f *Float) Float64() float64 {
 	f.doinit()
-	return float64(C.mpf_get_d(&f.i[0]))
+	r := float64(C.mpf_get_d(&f.i[0]))
+	runtime.KeepAlive(f)
+	return r
 }
 
 func (f *Float) Int64() int64 {
 	f.doinit()
-	return int64(C.mpf_get_si(&f.i[0]))
+	r := int64(C.mpf_get_si(&f.i[0]))
+	runtime.KeepAlive(f)
+	return r
 }
 
 // FIXME: Float2Exp is inconsistent, Float642Exp is silly.
@@ -139,37 +221,50 @@ func (f *Float) Int64() int64 {
 // Convert f to a float64, truncating if necessary (ie. rounding
 // towards zero), and with an exponent returned separately.
 func (f *Float) Float2Exp() (d float64, exp int) {
+	f.doinit()
 	var exp_ C.long
 	d = float64(C.mpf_get_d_2exp(&exp_, &f.i[0]))
 	exp = int(exp_)
+	runtime.KeepAlive(f)
 	return
 }
 
 func (f *Float) destroy() {
 	if f.init {
-		C.mpf_clear(&f.i[0])
+		f.copyCheck()
+		f.cleanup.Stop()
+		clearFloat(f.i)
 	}
+	f.i = nil
 	f.init = false
 }
 
+// Clear frees the space occupied by the underlying gmp object. Calling
+// Clear is optional: the space is also freed by a cleanup once f becomes
+// unreachable, but Clear releases it immediately. It is safe to call Clear
+// more than once, and f may be reused afterwards; it then holds the value 0.
 func (f *Float) Clear() {
 	f.destroy()
 }
 
 func (f *Float) GetPrec() uint {
 	f.doinit()
-	return uint(C.mpf_get_prec(&f.i[0]))
+	r := uint(C.mpf_get_prec(&f.i[0]))
+	runtime.KeepAlive(f)
+	return r
 }
 
 func (f *Float) SetPrec(prec uint) {
 	f.doinit()
 	C.mpf_set_prec(&f.i[0], C.mp_bitcnt_t(prec))
 	f.prec = prec
+	runtime.KeepAlive(f)
 }
 
 func (f *Float) SetPrecRaw(prec uint) {
 	f.doinit()
 	C.mpf_set_prec_raw(&f.i[0], C.mp_bitcnt_t(prec))
+	runtime.KeepAlive(f)
 }
 
 func SetDefaultPrec(prec uint) {
@@ -190,6 +285,8 @@ func (f *Float) Add(x, y *Float) *Float {
 	y.doinit()
 	f.doinit()
 	C.mpf_add(&f.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return f
 }
 
@@ -197,6 +294,7 @@ func (f *Float) AddUint(x *Float, y uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_add_ui(&f.i[0], &x.i[0], C.ulong(y))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -206,6 +304,8 @@ func (f *Float) Sub(x, y *Float) *Float {
 	y.doinit()
 	f.doinit()
 	C.mpf_sub(&f.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return f
 }
 
@@ -213,6 +313,7 @@ func (f *Float) SubUint(x *Float, y uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_sub_ui(&f.i[0], &x.i[0], C.ulong(y))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -222,6 +323,8 @@ func (f *Float) Mul(x, y *Float) *Float {
 	y.doinit()
 	f.doinit()
 	C.mpf_mul(&f.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return f
 }
 
@@ -229,6 +332,7 @@ func (f *Float) MulUint(x *Float, y uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_mul_ui(&f.i[0], &x.i[0], C.ulong(y))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -238,6 +342,8 @@ func (f *Float) Div(x, y *Float) *Float {
 	y.doinit()
 	f.doinit()
 	C.mpf_div(&f.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return f
 }
 
@@ -245,6 +351,7 @@ func (f *Float) DivUint(x *Float, y uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_div_ui(&f.i[0], &x.i[0], C.ulong(y))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -252,6 +359,7 @@ func (f *Float) UintDiv(x uint, y *Float) *Float {
 	y.doinit()
 	f.doinit()
 	C.mpf_ui_div(&f.i[0], C.ulong(x), &y.i[0])
+	runtime.KeepAlive(y)
 	return f
 }
 
@@ -260,6 +368,7 @@ func (f *Float) Sqrt(x *Float) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_sqrt(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -275,6 +384,7 @@ func (f *Float) PowUint(x *Float, y uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_pow_ui(&f.i[0], &x.i[0], C.ulong(y))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -283,6 +393,7 @@ func (f *Float) Neg(x *Float) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_neg(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -291,6 +402,7 @@ func (f *Float) Abs(x *Float) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_abs(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -299,6 +411,7 @@ func (f *Float) Mul2Exp(x *Float, s uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_mul_2exp(&f.i[0], &x.i[0], C.mp_bitcnt_t(s))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -307,6 +420,7 @@ func (f *Float) Div2Exp(x *Float, s uint) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_div_2exp(&f.i[0], &x.i[0], C.mp_bitcnt_t(s))
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -321,6 +435,8 @@ func (f *Float) RelDiff(x, y *Float) *Float {
 	y.doinit()
 	f.doinit()
 	C.mpf_reldiff(&f.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return f
 }
 
@@ -331,7 +447,9 @@ func (f *Float) Sgn() int {
 	// We need to watch out for changes in the data structure :(
 
 	//return int(C.mpf_sgn(&f.i[0]))
-	switch size := int(f.i[0]._mp_size); {
+	size := int(f.i[0]._mp_size)
+	runtime.KeepAlive(f)
+	switch {
 	case size < 0:
 		return -1
 	case size == 0:
@@ -353,22 +471,31 @@ func (f *Float) Sgn() int {
 func CmpFloat(x, y *Float) int {
 	x.doinit()
 	y.doinit()
-	return int(C.mpf_cmp(&x.i[0], &y.i[0]))
+	r := int(C.mpf_cmp(&x.i[0], &y.i[0]))
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	return r
 }
 
 func CmpFloatFloat64(x *Float, y float64) int {
 	x.doinit()
-	return int(C.mpf_cmp_d(&x.i[0], C.double(y)))
+	r := int(C.mpf_cmp_d(&x.i[0], C.double(y)))
+	runtime.KeepAlive(x)
+	return r
 }
 
 func CmpFloatUint(x *Float, y uint) int {
 	x.doinit()
-	return int(C.mpf_cmp_ui(&x.i[0], C.ulong(y)))
+	r := int(C.mpf_cmp_ui(&x.i[0], C.ulong(y)))
+	runtime.KeepAlive(x)
+	return r
 }
 
 func CmpFloatInt64(x *Float, y int64) int {
 	x.doinit()
-	return int(C.mpf_cmp_si(&x.i[0], C.long(y)))
+	r := int(C.mpf_cmp_si(&x.i[0], C.long(y)))
+	runtime.KeepAlive(x)
+	return r
 }
 
 // Return non-zero if the first n bits of x and y are equal,
@@ -376,13 +503,18 @@ func CmpFloatInt64(x *Float, y int64) int {
 func EqFloat(x, y *Float, n uint) int {
 	x.doinit()
 	y.doinit()
-	return int(C.mpf_eq(&x.i[0], &y.i[0], C.mp_bitcnt_t(n)))
+	r := int(C.mpf_eq(&x.i[0], &y.i[0], C.mp_bitcnt_t(n)))
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	return r
 }
 
 func SwapFloat(x, y *Float) {
 	x.doinit()
 	y.doinit()
 	C.mpf_swap(&x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 }
 
 // Sets f = Ceil(x) and returns f.
@@ -390,6 +522,7 @@ func (f *Float) Ceil(x *Float) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_ceil(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -398,6 +531,7 @@ func (f *Float) Floor(x *Float) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_floor(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
@@ -406,12 +540,15 @@ func (f *Float) Trunc(x *Float) *Float {
 	x.doinit()
 	f.doinit()
 	C.mpf_trunc(&f.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return f
 }
 
 func (f *Float) IsInteger() bool {
 	f.doinit()
-	return int(C.mpf_integer_p(&f.i[0])) != 0
+	r := int(C.mpf_integer_p(&f.i[0])) != 0
+	runtime.KeepAlive(f)
+	return r
 }
 
 //TODO(ug) mpf_fits_* and random functions
@@ -0,0 +1,614 @@
+//go:build !cgo
+
+package gmp
+
+import (
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// limbBits is the size of a gmp limb. Precisions are rounded up to a
+// multiple of it, like mpf_get_prec reports them.
+const limbBits = 64
+
+// defaultPrec is the precision of Floats that don't set one explicitly,
+// like mpf_set_default_prec.
+var defaultPrec uint = limbBits
+
+// limbPrec rounds prec up to a whole number of limbs.
+func limbPrec(prec uint) uint {
+	if prec == 0 {
+		return limbBits
+	}
+	return (prec + limbBits - 1) / limbBits * limbBits
+}
+
+// mantPrec returns the precision of the big.Float backing a Float of
+// precision prec. Like mpf, it keeps one more limb than GetPrec reports.
+func mantPrec(prec uint) uint {
+	return limbPrec(prec) + limbBits
+}
+
+// A Float represents a multi-precision floating point number.
+// The zero value for a Float represents the value 0.
+//
+// A Float must not be copied by value once it has been used; doing so
+// panics. Pass *Float instead.
+//
+// Without cgo, Float is a math/big.Float that truncates its results to
+// one more limb than the precision reported by GetPrec, like gmp. gmp
+// truncates at limb boundaries though, so the least significant bits of a
+// result may differ between the two implementations.
+type Float struct {
+	noCopy noCopy
+	addr   *Float // of receiver, to detect copies by value
+	i      big.Float
+	init   bool
+	prec   uint // 0 = use the default precision
+}
+
+// NewInt returns a new Int initialized to x.
+func NewFloat(x float64) *Float { return new(Float).SetFloat64(x) }
+
+// NewInt returns a new Int initialized to x, with precision prec.
+func NewFloat2(x float64, prec uint) *Float {
+	f := new(Float)
+	f.prec = prec
+	f.SetFloat64(x)
+	return f
+}
+
+// doinit sets the precision and rounding mode of f.i the first time f is
+// used.
+func (f *Float) doinit() {
+	if f.init {
+		f.copyCheck()
+		return
+	}
+	prec := f.prec
+	if prec == 0 {
+		prec = defaultPrec
+	}
+	f.i.SetPrec(mantPrec(prec)).SetMode(big.ToZero)
+	f.init = true
+	f.addr = f
+	trackInit(unsafe.Pointer(f), "Float")
+	trackCollect(unsafe.Pointer(f))
+}
+
+// copyCheck panics if f is an initialized Float that was copied by value.
+func (f *Float) copyCheck() {
+	if f.addr != f {
+		panic("gmp: illegal use of non-zero Float copied by value")
+	}
+}
+
+// Set sets f = x and returns f.
+func (f *Float) Set(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Set(&x.i)
+	return f
+}
+
+// SetInt sets f = x and returns f.
+func (f *Float) SetInt64(x int64) *Float {
+	f.doinit()
+	f.i.SetInt64(x)
+	return f
+}
+
+// SetUint64 sets f = x and returns f.
+func (f *Float) SetUint64(x uint64) *Float {
+	f.doinit()
+	f.i.SetUint64(x)
+	return f
+}
+
+// SetInt sets f = x, truncated to the precision of f, and returns f.
+func (f *Float) SetInt(x *Int) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.SetInt(x.ptr)
+	return f
+}
+
+// SetRat sets f = x, truncated to the precision of f, and returns f.
+func (f *Float) SetRat(x *Rat) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.SetRat(&x.i)
+	return f
+}
+
+// SetFloat64 sets f = x and returns f.
+func (f *Float) SetFloat64(x float64) *Float {
+	f.doinit()
+	f.i.SetFloat64(x)
+	return f
+}
+
+// SetString interprets s as a number in the given base
+// and sets f to that value.  The base must be in the range [2,MaxBase].
+// SetString returns an error if s cannot be parsed or the base is invalid.
+func (f *Float) SetString(s string, base int) error {
+	f.doinit()
+	if base < 2 || base > MaxBase {
+		return os.ErrInvalid
+	}
+	r, ok := parseFloat(s, base)
+	if !ok {
+		return os.ErrInvalid
+	}
+	f.i.SetRat(r)
+	return nil
+}
+
+// parseFloat parses s with the syntax of mpf_set_str: an optional minus
+// sign, a mantissa with an optional radix point, and an optional exponent
+// introduced by '@' (or 'e' for bases up to 10), which like the mantissa
+// is written in base. It returns the exact value of s.
+func parseFloat(s string, base int) (*big.Rat, bool) {
+	s = strings.TrimLeft(s, " \t\n\v\f\r")
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	mant, exp, hasExp := strings.Cut(s, "@")
+	if !hasExp && base <= 10 {
+		if i := strings.IndexAny(s, "eE"); i >= 0 {
+			mant, exp, hasExp = s[:i], s[i+1:], true
+		}
+	}
+	intPart, fracPart, _ := strings.Cut(mant, ".")
+	digits := intPart + fracPart
+	if digits == "" {
+		return nil, false
+	}
+	m := new(big.Int)
+	if !setIntString(m, digits, base) || strings.HasPrefix(digits, "-") {
+		return nil, false
+	}
+	e := -int64(len(fracPart))
+	if hasExp {
+		x := new(big.Int)
+		if !setIntString(x, strings.TrimPrefix(exp, "+"), base) || !x.IsInt64() {
+			return nil, false
+		}
+		e += x.Int64()
+	}
+	if neg {
+		m.Neg(m)
+	}
+	r := new(big.Rat).SetInt(m)
+	p := new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(abs64(e)), nil)
+	if e < 0 {
+		r.Quo(r, new(big.Rat).SetInt(p))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(p))
+	}
+	return r, true
+}
+
+func abs64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// floatDigits returns the digits of x in base, rounded to n significant
+// digits and without trailing zeros, and the exponent exp such that
+// x = 0.digits × base**exp, like mpf_get_str. A leading '-' is included
+// for negative x.
+func floatDigits(x *big.Float, base, n int) (string, int) {
+	if x.Sign() == 0 {
+		return "", 0
+	}
+	r, _ := x.Rat(nil)
+	neg := r.Sign() < 0
+	r.Abs(r)
+
+	b := big.NewInt(int64(base))
+	pow := func(e int) *big.Rat {
+		p := new(big.Int).Exp(b, big.NewInt(int64(abs64(int64(e)))), nil)
+		if e < 0 {
+			return new(big.Rat).SetFrac(big.NewInt(1), p)
+		}
+		return new(big.Rat).SetInt(p)
+	}
+
+	// Find exp with base**(exp-1) <= r < base**exp.
+	exp := int(math.Ceil(float64(x.MantExp(nil)) * math.Ln2 / math.Log(float64(base))))
+	for r.Cmp(pow(exp)) >= 0 {
+		exp++
+	}
+	for r.Cmp(pow(exp-1)) < 0 {
+		exp--
+	}
+
+	// Round r × base**(n-exp) to the nearest integer.
+	scaled := new(big.Rat).Mul(r, pow(n-exp))
+	q, m := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if m.Lsh(m, 1).Cmp(scaled.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	s := text(q, base)
+	if len(s) > n {
+		s = s[:n]
+		exp++
+	}
+	s = strings.TrimRight(s, "0")
+	if neg {
+		s = "-" + s
+	}
+	return s, exp
+}
+
+func (f *Float) StringBase(base int, ndigits uint) (string, error) {
+	if f == nil {
+		return "nil", nil
+	}
+	if base < 2 || base > MaxBase {
+		return "", os.ErrInvalid
+	}
+	f.doinit()
+	n := int(ndigits)
+	if n == 0 {
+		n = 2 + int(float64(f.i.Prec())*math.Ln2/math.Log(float64(base)))
+	}
+	s, exp := floatDigits(&f.i, base, n)
+
+	if len(s) == 0 {
+		return "0", nil
+	}
+
+	if exp > 0 && exp < len(s) {
+		return s[:exp] + "." + s[exp:], nil
+	}
+	return s + "e" + strconv.Itoa(exp), nil
+}
+
+// String returns the decimal representation of z.
+func (f *Float) String() string {
+	s, _ := f.StringBase(10, 0)
+	return s
+}
+
+func (f *Float) Float64() float64 {
+	f.doinit()
+	d, _ := new(big.Float).SetPrec(53).SetMode(big.ToZero).Set(&f.i).Float64()
+	return d
+}
+
+func (f *Float) Int64() int64 {
+	f.doinit()
+	i, _ := f.i.Int64()
+	return i
+}
+
+// FIXME: Float2Exp is inconsistent, Float642Exp is silly.
+
+// Convert f to a float64, truncating if necessary (ie. rounding
+// towards zero), and with an exponent returned separately.
+func (f *Float) Float2Exp() (d float64, exp int) {
+	f.doinit()
+	mant := new(big.Float)
+	exp = f.i.MantExp(mant)
+	d, _ = new(big.Float).SetPrec(53).SetMode(big.ToZero).Set(mant).Float64()
+	return
+}
+
+func (f *Float) destroy() {
+	if f.init {
+		f.copyCheck()
+		f.i = big.Float{}
+		trackClear(unsafe.Pointer(f))
+	}
+	f.init = false
+}
+
+// Clear releases the mantissa of f. Calling Clear is optional: without
+// cgo the memory is managed by the garbage collector. It is safe to call
+// Clear more than once, and f may be reused afterwards; it then holds the
+// value 0.
+func (f *Float) Clear() {
+	f.destroy()
+}
+
+func (f *Float) GetPrec() uint {
+	f.doinit()
+	return f.i.Prec() - limbBits
+}
+
+func (f *Float) SetPrec(prec uint) {
+	f.doinit()
+	f.i.SetPrec(mantPrec(prec))
+	f.prec = prec
+}
+
+func (f *Float) SetPrecRaw(prec uint) {
+	f.doinit()
+	f.i.SetPrec(mantPrec(prec))
+}
+
+func SetDefaultPrec(prec uint) {
+	defaultPrec = limbPrec(prec)
+}
+
+func GetDefaultPrec() uint {
+	return defaultPrec
+}
+
+/*
+ * arithmetic
+ */
+
+// checkDivisor panics if y is zero; gmp raises a division by zero too.
+func checkDivisor(y *big.Float) {
+	if y.Sign() == 0 {
+		panic("division by zero")
+	}
+}
+
+// Add sets f = x + y and returns f.
+func (f *Float) Add(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.i.Add(&x.i, &y.i)
+	return f
+}
+
+func (f *Float) AddUint(x *Float, y uint) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Add(&x.i, new(big.Float).SetUint64(uint64(y)))
+	return f
+}
+
+// Sub sets f = x - y and returns f.
+func (f *Float) Sub(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.i.Sub(&x.i, &y.i)
+	return f
+}
+
+func (f *Float) SubUint(x *Float, y uint) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Sub(&x.i, new(big.Float).SetUint64(uint64(y)))
+	return f
+}
+
+// Mul sets f = x * y and returns f.
+func (f *Float) Mul(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	f.i.Mul(&x.i, &y.i)
+	return f
+}
+
+func (f *Float) MulUint(x *Float, y uint) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Mul(&x.i, new(big.Float).SetUint64(uint64(y)))
+	return f
+}
+
+// Div sets f = x / y and returns f.
+func (f *Float) Div(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	checkDivisor(&y.i)
+	f.i.Quo(&x.i, &y.i)
+	return f
+}
+
+func (f *Float) DivUint(x *Float, y uint) *Float {
+	x.doinit()
+	f.doinit()
+	d := new(big.Float).SetUint64(uint64(y))
+	checkDivisor(d)
+	f.i.Quo(&x.i, d)
+	return f
+}
+
+func (f *Float) UintDiv(x uint, y *Float) *Float {
+	y.doinit()
+	f.doinit()
+	checkDivisor(&y.i)
+	f.i.Quo(new(big.Float).SetUint64(uint64(x)), &y.i)
+	return f
+}
+
+// Sqrt sets f = Sqrt(x) and returns f.
+func (f *Float) Sqrt(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Sqrt(&x.i)
+	return f
+}
+
+// Sqrt sets f = Sqrt(x) and returns f.
+func (f *Float) SqrtUint(x uint) *Float {
+	f.doinit()
+	f.i.Sqrt(new(big.Float).SetUint64(uint64(x)))
+	return f
+}
+
+// PowUint sets f = x^y and returns f
+func (f *Float) PowUint(x *Float, y uint) *Float {
+	x.doinit()
+	f.doinit()
+	// Square and multiply, truncating each step like mpf_pow_ui.
+	prec := f.i.Prec()
+	base := new(big.Float).SetPrec(prec).SetMode(big.ToZero).Set(&x.i)
+	z := new(big.Float).SetPrec(prec).SetMode(big.ToZero).SetInt64(1)
+	for ; y > 0; y >>= 1 {
+		if y&1 != 0 {
+			z.Mul(z, base)
+		}
+		base.Mul(base, base)
+	}
+	f.i.Set(z)
+	return f
+}
+
+// Neg sets z = -x and returns z.
+func (f *Float) Neg(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Neg(&x.i)
+	return f
+}
+
+// Abs sets z to the absolute value of x and returns z.
+func (f *Float) Abs(x *Float) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.Abs(&x.i)
+	return f
+}
+
+// Mul2Exp sets z = x * 2^s and returns z.
+func (f *Float) Mul2Exp(x *Float, s uint) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.SetMantExp(&x.i, int(s))
+	return f
+}
+
+// Div2Exp sets z = x / 2^s and returns z.
+func (f *Float) Div2Exp(x *Float, s uint) *Float {
+	x.doinit()
+	f.doinit()
+	f.i.SetMantExp(&x.i, -int(s))
+	return f
+}
+
+/*
+ * Comparison
+ */
+
+// Compute the relative difference between x and y and store the result in f.
+// This is abs(x-y)/x.
+func (f *Float) RelDiff(x, y *Float) *Float {
+	x.doinit()
+	y.doinit()
+	f.doinit()
+	checkDivisor(&x.i)
+	d := new(big.Float).SetPrec(f.i.Prec()).SetMode(big.ToZero)
+	d.Sub(&x.i, &y.i)
+	d.Abs(d)
+	f.i.Quo(d, &x.i)
+	return f
+}
+
+// Return +1 if f > 0, 0 if f = 0, and -1 if f < 0.
+func (f *Float) Sgn() int {
+	f.doinit()
+	return f.i.Sign()
+}
+
+/*
+ * functions without a clear receiver
+ */
+
+// CmpInt compares x and y. The result is
+//
+//	neg if x <  y
+//	 0 if x == y
+//	pos if x >  y
+func CmpFloat(x, y *Float) int {
+	x.doinit()
+	y.doinit()
+	return x.i.Cmp(&y.i)
+}
+
+func CmpFloatFloat64(x *Float, y float64) int {
+	x.doinit()
+	return x.i.Cmp(big.NewFloat(y))
+}
+
+func CmpFloatUint(x *Float, y uint) int {
+	x.doinit()
+	return x.i.Cmp(new(big.Float).SetUint64(uint64(y)))
+}
+
+func CmpFloatInt64(x *Float, y int64) int {
+	x.doinit()
+	return x.i.Cmp(new(big.Float).SetInt64(y))
+}
+
+// Return non-zero if the first n bits of x and y are equal,
+// zero otherwise.  I.e., test if x and y are approximately equal.
+func EqFloat(x, y *Float, n uint) int {
+	x.doinit()
+	y.doinit()
+	if x.i.Sign() != y.i.Sign() {
+		return 0
+	}
+	if x.i.Sign() == 0 {
+		return 1
+	}
+	mx, my := new(big.Float), new(big.Float)
+	if x.i.MantExp(mx) != y.i.MantExp(my) {
+		return 0
+	}
+	// Compare the mantissas truncated to n bits.
+	mx = new(big.Float).SetPrec(n).SetMode(big.ToZero).Set(mx)
+	my = new(big.Float).SetPrec(n).SetMode(big.ToZero).Set(my)
+	if mx.Cmp(my) != 0 {
+		return 0
+	}
+	return 1
+}
+
+func SwapFloat(x, y *Float) {
+	x.doinit()
+	y.doinit()
+	x.i, y.i = y.i, x.i
+	x.prec, y.prec = y.prec, x.prec
+}
+
+// Sets f = Ceil(x) and returns f.
+func (f *Float) Ceil(x *Float) *Float {
+	return f.round(x, 1)
+}
+
+// Sets f = Floor(x) and returns f.
+func (f *Float) Floor(x *Float) *Float {
+	return f.round(x, -1)
+}
+
+// Sets f = Trunc(x) (=round towards zero) and returns f.
+func (f *Float) Trunc(x *Float) *Float {
+	return f.round(x, 0)
+}
+
+// round sets f to x rounded to an integer towards zero, and then moved
+// away from zero by one if x isn't an integer and has the sign of dir.
+func (f *Float) round(x *Float, dir int) *Float {
+	x.doinit()
+	f.doinit()
+	i, acc := x.i.Int(nil)
+	if acc != big.Exact && x.i.Sign() == dir {
+		i.Add(i, big.NewInt(int64(dir)))
+	}
+	f.i.SetInt(i)
+	return f
+}
+
+func (f *Float) IsInteger() bool {
+	f.doinit()
+	return f.i.IsInt()
+}
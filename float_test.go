@@ -0,0 +1,456 @@
+package gmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFloatCopyCheck(t *testing.T) {
+	f := NewFloat(1.5)
+	var g Float
+	copyValue(&g, f)
+	expectCopyPanic(t, "Add", func() { g.Add(&g, f) })
+	expectCopyPanic(t, "Set", func() { new(Float).Set(&g) })
+	expectCopyPanic(t, "Clear", func() { g.Clear() })
+}
+
+func TestFloatInStruct(t *testing.T) {
+	p := new(struct{ a, b Float })
+	p.b.SetInt64(5)
+	s := make([]Float, 3)
+	s[1].SetInt64(6)
+	runtime.GC()
+	p.a.Add(&p.b, &s[1])
+	if p.a.Int64() != 11 {
+		t.Errorf("got %s; want 11", &p.a)
+	}
+	// A zero value is a valid source too.
+	if p.a.Set(&s[2]).Sgn() != 0 {
+		t.Errorf("Set(zero value) = %s; want 0", &p.a)
+	}
+	if d, exp := s[0].Float2Exp(); d != 0 || exp != 0 {
+		t.Errorf("Float2Exp of zero value = %g, %d; want 0, 0", d, exp)
+	}
+}
+
+func TestFloatScan(t *testing.T) {
+	for i, test := range []struct {
+		input, format string
+		output        float64
+		remaining     int
+	}{
+		{"1.5", "%v", 1.5, 0},
+		{"-3.125e-2", "%g", -3.125e-2, 0},
+		{"+2E+2", "%e", 200, 0},
+		{".5", "%f", 0.5, 0},
+		{"3.", "%v", 3, 0},
+		{"42 rest", "%v", 42, 5},
+	} {
+		x := new(Float)
+		buf := bytes.NewBufferString(test.input)
+		if _, err := fmt.Fscanf(buf, test.format, x); err != nil {
+			t.Errorf("#%d error: %s", i, err)
+		}
+		if got := x.Float64(); got != test.output {
+			t.Errorf("#%d got %v; want %v", i, got, test.output)
+		}
+		if buf.Len() != test.remaining {
+			t.Errorf("#%d got %d bytes remaining; want %d", i, buf.Len(), test.remaining)
+		}
+	}
+
+	for _, input := range []string{"", ".", "-", "7e", "1e+"} {
+		if _, err := fmt.Sscan(input, new(Float)); err == nil {
+			t.Errorf("Sscan(%q) succeeded", input)
+		}
+	}
+
+	// Fscan reads one value after the other.
+	var x, y Float
+	if _, err := fmt.Sscan("0.25 -8", &x, &y); err != nil {
+		t.Fatal(err)
+	}
+	if x.Float64() != 0.25 || y.Float64() != -8 {
+		t.Errorf("got %v %v; want 0.25 -8", x.Float64(), y.Float64())
+	}
+}
+
+func TestFloatMarshalText(t *testing.T) {
+	for _, test := range []struct {
+		x    float64
+		want string
+	}{
+		{0, "0"},
+		{1.5, "1.5"},
+		{-0.25, "-0.25"},
+		{1 << 60, "1152921504606846976"},
+		// Shorter integers would be kept exactly by UnmarshalText.
+		{1 << 70, "1.180591620717411303424e21"},
+		{1 << 80, "1.208925819614629174706176e24"},
+		{0.0009765625, "0.0009765625"},
+		{0.00006103515625, "6.103515625e-5"},
+	} {
+		text, err := NewFloat(test.x).MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(text) != test.want {
+			t.Errorf("MarshalText(%v) = %s; want %s", test.x, text, test.want)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, prec := range []uint{1, 53, 64, 100, 256, 1000} {
+		for i := 0; i < 50; i++ {
+			x := NewFloat2(0, prec)
+			x.SetInt64(r.Int63())
+			x.Div(x, NewFloat2(float64(r.Int63()), prec))
+			if i%2 == 1 {
+				x.Neg(x)
+			}
+			if i%3 == 0 {
+				x.Mul2Exp(x, uint(r.Intn(500)))
+			} else {
+				x.Div2Exp(x, uint(r.Intn(500)))
+			}
+			text, err := x.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			y := NewFloat2(0, prec)
+			if err := y.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+			if CmpFloat(y, x) != 0 || y.GetPrec() != x.GetPrec() {
+				t.Errorf("prec %d: %s did not round-trip", prec, text)
+			}
+		}
+	}
+
+	// gmp keeps bits of 1/3 beyond the precision, which must survive.
+	for _, prec := range []uint{53, 64, 100, 200} {
+		x := NewFloat2(1, prec)
+		x.Div(x, NewFloat2(3, prec))
+		text, _ := x.MarshalText()
+		y := NewFloat2(0, prec)
+		if err := y.UnmarshalText(text); err != nil || CmpFloat(y, x) != 0 {
+			t.Errorf("prec %d: 1/3 decoded to %s, %v; want %s", prec, y, err, x)
+		}
+	}
+
+	if err := new(Float).UnmarshalText([]byte("1.5x")); err == nil {
+		t.Error("UnmarshalText(1.5x) succeeded")
+	}
+}
+
+func TestFloatJSON(t *testing.T) {
+	x := NewFloat(-2.5e-3)
+	for _, test := range []struct {
+		quote bool
+		want  string
+	}{
+		{false, `-0.0025000000000000000521`},
+		{true, `"-0.0025000000000000000521"`},
+	} {
+		old := SetJSONQuote(test.quote)
+		b, err := json.Marshal(x)
+		SetJSONQuote(old)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("quote %v: got %s; want %s", test.quote, b, test.want)
+		}
+		var y *Float
+		if err := json.Unmarshal(b, &y); err != nil {
+			t.Fatal(err)
+		}
+		if CmpFloat(x, y) != 0 {
+			t.Errorf("quote %v: %s decoded to %s", test.quote, b, y)
+		}
+	}
+
+	y := NewFloat(1)
+	if err := json.Unmarshal([]byte("null"), &y); err != nil || y != nil {
+		t.Errorf("null decoded to %v, %v", y, err)
+	}
+}
+
+func TestFloatBinary(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, prec := range []uint{53, 64, 100, 1000} {
+		for i := 0; i < 20; i++ {
+			x := NewFloat2(0, prec)
+			if i > 0 {
+				x.SetInt64(r.Int63() - r.Int63())
+				x.Div(x, NewFloat2(float64(r.Int63()), prec))
+				x.Mul2Exp(x, uint(r.Intn(200)))
+			}
+			b, err := x.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			y := new(Float)
+			if err := y.UnmarshalBinary(b); err != nil {
+				t.Fatal(err)
+			}
+			if y.GetPrec() != x.GetPrec() {
+				t.Errorf("decoded precision %d; want %d", y.GetPrec(), x.GetPrec())
+			}
+			if CmpFloat(y, x) != 0 {
+				t.Errorf("prec %d: %s decoded to %s", prec, x, y)
+			}
+		}
+	}
+
+	// gmp keeps bits of 1/3 beyond the precision, which must survive.
+	x := NewFloat2(1, 64)
+	x.Div(x, NewFloat2(3, 64))
+	b, _ := x.MarshalBinary()
+	third := new(Float)
+	if err := third.UnmarshalBinary(b); err != nil || CmpFloat(third, x) != 0 {
+		t.Errorf("1/3 decoded to %s, %v; want %s", third, err, x)
+	}
+
+	// A mantissa longer than the precision, or a precision that is 0 or
+	// would make gmp allocate gigabytes, is rejected.
+	x = NewFloat2(1, 1000)
+	x.Div(x, NewFloat2(3, 1000))
+	b, _ = x.MarshalBinary()
+	binary.BigEndian.PutUint32(b[1:5], 64)
+	for _, b := range [][]byte{
+		b,
+		{2, 0, 0, 0, 0},
+		{2, 0xff, 0xff, 0xff, 0xff},
+		{2, 0x01, 0, 0, 1},
+	} {
+		if err := new(Float).UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%x) succeeded", b)
+		}
+	}
+
+	x = NewFloat(-0.75)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(x); err != nil {
+		t.Fatal(err)
+	}
+	var y Float
+	if err := gob.NewDecoder(&buf).Decode(&y); err != nil {
+		t.Fatal(err)
+	}
+	if y.Float64() != -0.75 {
+		t.Errorf("gob decoded %s; want -0.75", &y)
+	}
+}
+
+func TestFloatText(t *testing.T) {
+	x := NewFloat(-1234.5678)
+	for _, test := range []struct {
+		format byte
+		prec   int
+		want   string
+	}{
+		{'e', 3, "-1.235e+03"},
+		{'E', -1, "-1.2345678000000000338E+03"},
+		{'f', 2, "-1234.57"},
+		{'g', -1, "-1234.5678000000000338"},
+		{'g', 3, "-1.23e+03"},
+		{'b', 0, "-11119998168087226368p-53"},
+		{'p', 0, "-0x.9a522b6ae7d568p+11"},
+		{'x', 4, "-0x1.34a4p+10"},
+	} {
+		if got := x.Text(test.format, test.prec); got != test.want {
+			t.Errorf("Text(%c, %d) = %s; want %s", test.format, test.prec, got, test.want)
+		}
+		if got := string(x.Append([]byte("x="), test.format, test.prec)); got != "x="+test.want {
+			t.Errorf("Append(%c, %d) = %s; want x=%s", test.format, test.prec, got, test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		format string
+		x      *Float
+		want   string
+	}{
+		{"%v", NewFloat(0.25), "0.25"},
+		{"%.3f", NewFloat(2.5), "2.500"},
+		{"%+10.2e", NewFloat(1e-7), " +1.00e-07"},
+		{"%-8g|", NewFloat(1.5), "1.5     |"},
+		{"%08.3f", NewFloat(-3.25), "-003.250"},
+		{"%x", NewFloat(1), "0x1.000000p+00"},
+		{"%v", nil, "<nil>"},
+		{"%d", NewFloat(1), "%!d(*gmp.Float=1)"},
+	} {
+		if got := fmt.Sprintf(test.format, test.x); got != test.want {
+			t.Errorf("Sprintf(%q) = %q; want %q", test.format, got, test.want)
+		}
+	}
+}
+
+func TestFloatParse(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		base int
+		want float64
+		n    int
+	}{
+		{"0x1.8p-3", 0, 0.1875, 8},
+		{"-0X_1F", 0, -31, 6},
+		{"0b101.1", 0, 5.5, 7},
+		{"0o17p1", 0, 30, 6},
+		{"1_000.5e-1", 0, 100.05, 10},
+		{"+.5", 0, 0.5, 3},
+		{"12e", 0, 12, 2},
+		{"0x", 0, 0, 1},
+		{"1.5x", 0, 1.5, 3},
+		{"1_", 0, 1, 1},
+		{"1__0", 0, 1, 1},
+		{"1_0", 10, 1, 1},
+		{"ff.8", 16, 255.5, 4},
+		{"ff@-1", 16, 15.9375, 5},
+		{"z@1", 36, 35 * 36, 3},
+		{"12@2", 3, 45, 4},
+		{"1e3", 16, 0x1e3, 3},
+		{"a", 37, 36, 1},
+		{"Z", 40, 35, 1},
+		{"Zz", 40, 35, 1},
+		{"Aa", 62, 10*62 + 36, 2},
+		{"z.V@1", 62, 61*62 + 31, 5},
+	} {
+		x := NewFloat2(0, 128)
+		f, n, err := x.Parse(test.s, test.base)
+		if err != nil {
+			t.Errorf("Parse(%q, %d): %v", test.s, test.base, err)
+			continue
+		}
+		if f != x || f.Float64() != test.want || n != test.n {
+			t.Errorf("Parse(%q, %d) = %v, %d; want %v, %d", test.s, test.base, f.Float64(), n, test.want, test.n)
+		}
+	}
+
+	// The result is truncated to the precision of z.
+	x := NewFloat2(0, 64)
+	if _, _, err := x.Parse("0.1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := x.Text('p', 0), "0x.ccccccccccccccccp-3"; got != want {
+		t.Errorf("Parse(0.1) = %s; want %s", got, want)
+	}
+
+	for _, test := range []struct {
+		s    string
+		base int
+	}{
+		{"", 0},
+		{"-", 0},
+		{".", 0},
+		{"_1", 0},
+		{"x", 0},
+		{"1", 1},
+		{"1", 63},
+		{"1e1000000000", 0},
+		{"0x1p99999999999", 0},
+	} {
+		if _, _, err := new(Float).Parse(test.s, test.base); err == nil {
+			t.Errorf("Parse(%q, %d) succeeded", test.s, test.base)
+		}
+	}
+}
+
+func TestFloatCBOR(t *testing.T) {
+	for _, test := range []struct {
+		x       string
+		bigfl   string
+		decimal string
+	}{
+		{"0", "c5820000", "c4820000"},
+		{"1.5", "c5822003", "c482200f"},
+		{"-3", "c5820022", "c4820022"},
+		{"0.25", "c5822101", "c4822118 19"},
+		{"100", "c582021819", "c4820201"},
+		{"1180591620717411303424", "c582184601", "c48200c2494000000000000000 00"},
+	} {
+		x := NewFloat2(0, 64)
+		x.SetString(test.x, 10)
+		for _, enc := range []struct {
+			name    string
+			marshal func() ([]byte, error)
+			want    string
+		}{
+			{"MarshalCBOR", x.MarshalCBOR, test.bigfl},
+			{"MarshalCBORDecimal", x.MarshalCBORDecimal, test.decimal},
+		} {
+			want := strings.ReplaceAll(enc.want, " ", "")
+			data, err := enc.marshal()
+			if err != nil || hex.EncodeToString(data) != want {
+				t.Errorf("%s(%s) = %x, %v; want %s", enc.name, test.x, data, err, want)
+			}
+			y := NewFloat2(0, 64)
+			if err := y.UnmarshalCBOR(data); err != nil || CmpFloat(y, x) != 0 {
+				t.Errorf("UnmarshalCBOR(%x) = %s, %v; want %s", data, y, err, x)
+			}
+		}
+	}
+
+	// Bigfloats and integers are exact, other values truncated.
+	x := NewFloat2(0, 64)
+	data, _ := hex.DecodeString("c58220c24901" + strings.Repeat("00", 7) + "01")
+	if err := x.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := new(Rat).SetString("18446744073709551617/2"); new(Rat).SetFloat(x).Cmp(want) != 0 {
+		t.Errorf("UnmarshalCBOR(%x) = %s; want %s", data, x, want)
+	}
+	x = NewFloat2(0, 64)
+	data, _ = hex.DecodeString("c48221196ab3")
+	if err := x.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	want := NewFloat2(0, 64)
+	want.UnmarshalText([]byte("273.15"))
+	if CmpFloat(x, want) != 0 || x.GetPrec() != want.GetPrec() {
+		t.Errorf("UnmarshalCBOR(%x) = %s; want %s", data, x, want)
+	}
+
+	// Round trips at higher precision.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		x := NewFloat2(r.NormFloat64(), 200)
+		x.Mul(x, NewFloat2(r.NormFloat64(), 200))
+		x.Mul2Exp(x, uint(r.Intn(300)))
+		x.Div2Exp(x, uint(r.Intn(300)))
+		for _, marshal := range []func() ([]byte, error){x.MarshalCBOR, x.MarshalCBORDecimal} {
+			data, _ := marshal()
+			y := NewFloat2(0, 200)
+			if err := y.UnmarshalCBOR(data); err != nil || CmpFloat(y, x) != 0 {
+				t.Errorf("UnmarshalCBOR(%x) = %s, %v; want %s", data, y, err, x)
+			}
+		}
+	}
+
+	// gmp keeps bits of 1/3 beyond the precision, which must survive.
+	third := NewFloat2(1, 64)
+	third.Div(third, NewFloat2(3, 64))
+	for _, marshal := range []func() ([]byte, error){third.MarshalCBOR, third.MarshalCBORDecimal} {
+		data, _ := marshal()
+		y := NewFloat2(0, 64)
+		if err := y.UnmarshalCBOR(data); err != nil || CmpFloat(y, third) != 0 || y.GetPrec() != 64 {
+			t.Errorf("UnmarshalCBOR(%x) = %s, %v; want %s", data, y, err, third)
+		}
+	}
+
+	for _, s := range []string{"", "c582", "c58200", "c58200c4820000", "c5821a7fffffff01", "d81e820100", "c6820000"} {
+		data, _ := hex.DecodeString(s)
+		if err := NewFloat2(0, 64).UnmarshalCBOR(data); err == nil {
+			t.Errorf("UnmarshalCBOR(%s) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package gmp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Scan is a support routine for fmt.Scanner; it sets f to the value of
+// the scanned number. It accepts the formats 'e', 'E', 'f', 'F', 'g', 'G'
+// and 'v', which all read a decimal number with an optional fraction and
+// exponent, like "-1.25e-3".
+func (f *Float) Scan(s fmt.ScanState, ch rune) error {
+	s.SkipSpace() // skip leading space characters
+	switch ch {
+	case 'e', 'E', 'f', 'F', 'g', 'G', 'v':
+	default:
+		return errors.New("gmp: Float.Scan: invalid verb")
+	}
+
+	neg, err := scanSign(s)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	if neg {
+		buf = append(buf, '-')
+	}
+
+	// mantissa
+	n := len(buf)
+	buf, _ = scanDigits(s, 10, buf)
+	if ok, err := scanRune(s, '.'); err != nil {
+		return err
+	} else if ok {
+		buf = append(buf, '.')
+		buf, _ = scanDigits(s, 10, buf)
+	}
+	if len(buf) == n || string(buf[n:]) == "." {
+		return errors.New("gmp: Float.Scan: number has no digits")
+	}
+
+	// exponent
+	ok, err := scanRune(s, 'e')
+	if err == nil && !ok {
+		ok, err = scanRune(s, 'E')
+	}
+	if err != nil {
+		return err
+	}
+	if ok {
+		buf = append(buf, 'e')
+		neg, err := scanSign(s)
+		if err != nil {
+			return err
+		}
+		if neg {
+			buf = append(buf, '-')
+		}
+		if buf, err = scanDigits(s, 10, buf); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetString(string(buf), 10); err != nil {
+		return errors.New("gmp: Float.Scan: invalid syntax")
+	}
+	return nil
+}
+
+// scanRune reads the next rune from s if it is ch and reports whether it
+// did. Reaching the end of the input is not an error.
+func scanRune(s fmt.ScanState, ch rune) (bool, error) {
+	r, _, err := s.ReadRune()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if r != ch {
+		s.UnreadRune()
+		return false, nil
+	}
+	return true, nil
+}
+
+// Text converts the floating-point number x to a string according to the
+// given format and precision prec, like math/big.Float.Text. The format is
+// one of:
+//
+//	'e'	-d.dddde±dd, decimal exponent, at least two (possibly 0) exponent digits
+//	'E'	-d.ddddE±dd, decimal exponent, at least two (possibly 0) exponent digits
+//	'f'	-ddddd.dddd, no exponent
+//	'g'	like 'e' for large exponents, like 'f' otherwise
+//	'G'	like 'E' for large exponents, like 'f' otherwise
+//	'x'	-0xd.dddddp±dd, hexadecimal mantissa, decimal power of two exponent
+//	'p'	-0x.dddp±dd, hexadecimal mantissa, decimal power of two exponent (non-standard)
+//	'b'	-ddddddp±dd, decimal mantissa, decimal power of two exponent (non-standard)
+//
+// For the binary exponent formats, the mantissa is printed in normalized
+// form. For the decimal formats, prec is the number of digits after the
+// decimal point ('e', 'E', 'f') or the maximum number of significant digits
+// ('g', 'G'); a negative prec selects the smallest number of digits needed
+// to represent x uniquely at its precision. Decimal digits are rounded to
+// nearest even. If gmp keeps bits of x beyond GetPrec() in an extra limb,
+// they are formatted too, at the precision that covers them.
+func (x *Float) Text(format byte, prec int) string {
+	if x == nil {
+		return "<nil>"
+	}
+	return x.exact().Text(format, prec)
+}
+
+// Append appends to buf the string form of the floating-point number x,
+// as generated by x.Text, and returns the extended buffer.
+func (x *Float) Append(buf []byte, format byte, prec int) []byte {
+	if x == nil {
+		return append(buf, "<nil>"...)
+	}
+	return x.exact().Append(buf, format, prec)
+}
+
+var _ fmt.Formatter = (*Float)(nil) // *Float must implement fmt.Formatter
+
+// Format implements fmt.Formatter. It accepts all the regular formats for
+// floating-point numbers ('b', 'e', 'E', 'f', 'F', 'g', 'G', 'x') as well
+// as 'p' and 'v', like math/big.Float.Format. 'v' is handled like 'g'.
+// Format also supports the output field width, as well as the format flags
+// '+' and ' ' for sign control, '0' for space or zero padding, and '-' for
+// left or right justification.
+func (x *Float) Format(s fmt.State, format rune) {
+	switch format {
+	case 'b', 'e', 'E', 'f', 'F', 'g', 'G', 'x', 'X', 'p', 'v':
+	default:
+		fmt.Fprintf(s, "%%!%c(*gmp.Float=%s)", format, x.Text('g', 10))
+		return
+	}
+	if x == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+	x.exact().Format(s, format)
+}
+
+// maxParseExp bounds the exponents of powers of the base and of 10 that
+// Parse computes exactly.
+const maxParseExp = 1e6
+
+// Parse parses the longest prefix of s that is a floating-point number in
+// the given base, sets z to its value truncated to the precision of z, and
+// returns z and the number of bytes of s it consumed. Parse returns an
+// error if s has no such prefix.
+//
+// The number has the form
+//
+//	number   = [ sign ] [ prefix ] mantissa [ exponent ] .
+//	sign     = "+" | "-" .
+//	prefix   = "0b" | "0B" | "0o" | "0O" | "0x" | "0X" .
+//	mantissa = digits "." [ digits ] | digits | "." digits .
+//	exponent = ( "e" | "E" | "p" | "P" | "@" ) [ sign ] decimal digits .
+//
+// The base must be 0 or in the range [2,MaxBase]. With base 0, the prefix
+// selects base 2, 8 or 16, and the number is decimal without one; in that
+// case underscores may also separate digits, like in Go literals. Prefixes
+// are not allowed for other bases.
+//
+// The exponent is always written in decimal. "e" or "E" multiplies the
+// mantissa by a power of 10 and is only allowed in base 10. "p" or "P"
+// multiplies it by a power of 2 and is only allowed in bases 2, 8 and 16,
+// so hexadecimal floats like "0x1.8p-3" parse as in Go. "@" multiplies it
+// by a power of the base in any base, like in mpf_set_str.
+func (z *Float) Parse(s string, base int) (f *Float, n int, err error) {
+	if base != 0 && (base < 2 || base > MaxBase) {
+		return nil, 0, fmt.Errorf("gmp: Float.Parse: invalid base %d", base)
+	}
+	r, e2, n, err := parseFloatPrefix(s, base)
+	if err != nil {
+		return nil, 0, err
+	}
+	x := new(big.Float).SetPrec(z.GetPrec()).SetMode(big.ToZero).SetRat(r)
+	z.SetBig(x.SetMantExp(x, e2))
+	return z, n, nil
+}
+
+// parseFloatPrefix parses the longest prefix of s with the syntax of
+// Float.Parse. It returns the exact value r × 2**e2 and the length of the
+// prefix.
+func parseFloatPrefix(s string, base int) (r *big.Rat, e2 int, n int, err error) {
+	i := 0
+	neg := false
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		neg = s[i] == '-'
+		i++
+	}
+
+	// prefix and mantissa
+	b, seps, prefixed := base, false, false
+	if base == 0 {
+		b, seps = 10, true
+		if i+1 < len(s) && s[i] == '0' {
+			pb := 0
+			switch s[i+1] {
+			case 'b', 'B':
+				pb = 2
+			case 'o', 'O':
+				pb = 8
+			case 'x', 'X':
+				pb = 16
+			}
+			// Without a mantissa after it, the prefix is just a 0.
+			if pb != 0 {
+				if _, _, end := scanMantissa(s, i+2, pb, true, true); end >= 0 {
+					b, prefixed = pb, true
+					i += 2
+				}
+			}
+		}
+	}
+	mant, frac, end := scanMantissa(s, i, b, seps, prefixed)
+	if end < 0 {
+		return nil, 0, 0, fmt.Errorf("gmp: Float.Parse: invalid syntax %q", s)
+	}
+	i = end
+
+	// exponent, as a power of b and of 2
+	eb := -int64(frac)
+	if i < len(s) {
+		c := s[i]
+		if (c == 'e' || c == 'E') && b == 10 ||
+			(c == 'p' || c == 'P') && (b == 2 || b == 8 || b == 16) ||
+			c == '@' {
+			if x, end := scanExponent(s, i+1); end >= 0 {
+				i = end
+				if c == 'p' || c == 'P' {
+					if x < -1<<30 || x > 1<<30 {
+						return nil, 0, 0, fmt.Errorf("gmp: Float.Parse: exponent of %q out of range", s[:i])
+					}
+					e2 = int(x)
+				} else {
+					eb += x
+				}
+			}
+		}
+	}
+	if eb < -maxParseExp || eb > maxParseExp {
+		return nil, 0, 0, fmt.Errorf("gmp: Float.Parse: exponent of %q out of range", s[:i])
+	}
+
+	if b > 36 {
+		swapCase(mant)
+	}
+	m, ok := new(big.Int).SetString(string(mant), b)
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("gmp: Float.Parse: invalid syntax %q", s)
+	}
+	if neg {
+		m.Neg(m)
+	}
+	r = new(big.Rat).SetInt(m)
+	p := new(big.Int).Exp(big.NewInt(int64(b)), big.NewInt(int64(abs(int(eb)))), nil)
+	if eb < 0 {
+		r.Quo(r, new(big.Rat).SetInt(p))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(p))
+	}
+	return r, e2, i, nil
+}
+
+// scanMantissa scans digits in base starting at s[i], with at most one
+// radix point among them. If seps is set, underscores may separate digits,
+// and also follow a prefix just before s[i] if prefixed is set. It
+// returns the digits, how many of them follow the radix point, and the
+// index after the mantissa, or -1 if there are no digits.
+func scanMantissa(s string, i, base int, seps, prefixed bool) (mant []byte, frac int, end int) {
+	dot := false
+	j := i
+loop:
+	for ; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case digitValue(rune(c), base) < base:
+			mant = append(mant, c)
+			if dot {
+				frac++
+			}
+		case c == '_' && seps && j+1 < len(s) && digitValue(rune(s[j+1]), base) < base &&
+			(j > i && digitValue(rune(s[j-1]), base) < base || j == i && prefixed):
+			// separator
+		case c == '.' && !dot:
+			dot = true
+		default:
+			break loop
+		}
+	}
+	if len(mant) == 0 {
+		return nil, 0, -1
+	}
+	return mant, frac, j
+}
+
+// scanExponent scans an optionally signed decimal integer starting at
+// s[i]. It returns its value, clamped to ±1<<62, and the index after it,
+// or -1 if there are no digits.
+func scanExponent(s string, i int) (x int64, end int) {
+	neg := false
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		neg = s[i] == '-'
+		i++
+	}
+	j := i
+	for ; j < len(s) && '0' <= s[j] && s[j] <= '9'; j++ {
+		if x <= 1<<62/10 {
+			x = x*10 + int64(s[j]-'0')
+		}
+	}
+	if j == i {
+		return 0, -1
+	}
+	if x > 1<<62 {
+		x = 1 << 62
+	}
+	if neg {
+		x = -x
+	}
+	return x, j
+}
@@ -0,0 +1,351 @@
+package gmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Gob codec version. Permits backward-compatible changes to the encoding.
+const floatGobVersion byte = 1
+
+// maxBinaryPrec is the largest precision MarshalBinary encodes and
+// UnmarshalBinary accepts, so that a few bytes of untrusted input can't
+// make gmp allocate gigabytes of limbs.
+const maxBinaryPrec = 1 << 24
+
+// MarshalText implements the encoding.TextMarshaler interface. The result
+// is the shortest decimal number that UnmarshalText turns back into x when
+// the receiver has the precision of x. If gmp keeps bits of x beyond
+// GetPrec() in an extra limb, the result is the exact value of x instead,
+// which UnmarshalText restores with those bits.
+func (x *Float) MarshalText() (text []byte, err error) {
+	if x == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(x.exactString()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It
+// accepts decimal numbers with an optional fraction and exponent, like
+// "-1.25e-3". The result is truncated to the precision of z, unless z can
+// hold it exactly with bits in the extra limb beyond GetPrec().
+//
+// mpf_set_str only considers as many digits as the precision of z needs
+// and may be off in the last limb, so UnmarshalText parses text exactly
+// and truncates it once.
+func (z *Float) UnmarshalText(text []byte) error {
+	r, ok := parseDecimal(string(text))
+	if !ok {
+		return fmt.Errorf("gmp: cannot unmarshal %q into a *gmp.Float", text)
+	}
+	z.setTruncated(r)
+	return nil
+}
+
+// setTruncated sets z to r exactly if z can hold r, using the extra limb
+// beyond GetPrec() if needed, and to r truncated to the precision of z
+// otherwise.
+func (z *Float) setTruncated(r *big.Rat) {
+	if d := r.Denom(); isPow2(d) {
+		num := r.Num()
+		f := new(big.Float).SetPrec(uint(max(num.BitLen(), 1))).SetInt(num)
+		if z.setExact(f.SetMantExp(f, -int(d.TrailingZeroBits()))) {
+			return
+		}
+	}
+	z.SetBig(new(big.Float).SetPrec(z.GetPrec()).SetMode(big.ToZero).SetRat(r))
+}
+
+// isPow2 reports whether the positive n is a power of 2.
+func isPow2(n *big.Int) bool {
+	return n.BitLen()-1 == int(n.TrailingZeroBits())
+}
+
+// exactString returns the shortest decimal representation of x that
+// UnmarshalText turns back into x at the precision of x, or the exact
+// decimal value of x if it has bits beyond GetPrec().
+func (x *Float) exactString() string {
+	b := x.exact()
+	if b.Sign() == 0 {
+		return "0"
+	}
+	neg := b.Signbit()
+	var digits string
+	var e int
+	if b.Prec() > x.GetPrec() {
+		digits, e = exactDigits(x)
+	} else {
+		digits, e = shortestDigits(b.Abs(b))
+	}
+
+	// |x| = 0.digits × 10**e
+	var s string
+	switch {
+	case -4 < e && e <= 0:
+		s = "0." + strings.Repeat("0", -e) + digits
+	case 0 < e && e <= 21:
+		if len(digits) <= e {
+			s = digits + strings.Repeat("0", e-len(digits))
+		} else {
+			s = digits[:e] + "." + digits[e:]
+		}
+	default:
+		s = digits[:1]
+		if len(digits) > 1 {
+			s += "." + digits[1:]
+		}
+		s += "e" + strconv.Itoa(e-1)
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// exactDigits returns the decimal digits of |x|, without trailing zeros,
+// and the exponent e with |x| = 0.digits × 10**e.
+func exactDigits(x *Float) (digits string, e int) {
+	m, exp := x.oddMantExp()
+	m.Abs(m)
+	if exp >= 0 {
+		m.Lsh(m, uint(exp))
+		exp = 0
+	} else {
+		// m × 2**exp = m × 5**-exp × 10**exp
+		m.Mul(m, new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(-exp)), nil))
+	}
+	digits = m.String()
+	return strings.TrimRight(digits, "0"), len(digits) + exp
+}
+
+// shortestDigits returns the fewest decimal digits, without trailing
+// zeros, that UnmarshalText truncates back to the positive b at the
+// precision of b, and the exponent e with the digits meaning 0.digits ×
+// 10**e.
+//
+// UnmarshalText truncates, so the digits must not be below b: they are
+// rounded away from zero instead of to the nearest. They must also stay
+// below the next value with the precision of b, or truncation would end up
+// there instead. Finally, UnmarshalText keeps values a Float can hold
+// exactly, so the digits must be b or a value with a denominator other
+// than a power of 2.
+func shortestDigits(b *big.Float) (digits string, e int) {
+	prec := b.Prec()
+	r, _ := b.Rat(nil)
+
+	// 2**(exp-prec) is the distance to the next value with prec bits.
+	exp := b.MantExp(nil)
+	ulp, _ := new(big.Float).SetMantExp(big.NewFloat(1), exp-int(prec)).Rat(nil)
+	limit := ulp.Add(ulp, r)
+
+	// Find e with 10**(e-1) <= r < 10**e.
+	e = int(math.Ceil(float64(exp) * math.Log10(2)))
+	for r.Cmp(pow10(e)) >= 0 {
+		e++
+	}
+	for r.Cmp(pow10(e-1)) < 0 {
+		e--
+	}
+
+	// ceil returns r rounded up to n significant digits, as an integer
+	// to be scaled by 10**(e-n).
+	ceil := func(n int) *big.Rat {
+		s := new(big.Rat).Mul(r, pow10(n-e))
+		q, m := new(big.Int).QuoRem(s.Num(), s.Denom(), new(big.Int))
+		if m.Sign() != 0 {
+			q.Add(q, big.NewInt(1))
+		}
+		return s.SetInt(q)
+	}
+	value := func(n int) *big.Rat {
+		d := ceil(n)
+		return d.Mul(d, pow10(e-n))
+	}
+
+	// More digits never hurt, so binary search for the fewest that fit.
+	lo, hi := 1, int(math.Ceil(float64(prec)*math.Log10(2)))+1
+	for lo < hi {
+		if n := (lo + hi) / 2; value(n).Cmp(limit) < 0 {
+			hi = n
+		} else {
+			lo = n + 1
+		}
+	}
+	for d := value(lo); d.Cmp(r) != 0 && isPow2(d.Denom()); d = value(lo) {
+		lo++
+	}
+	digits = ceil(lo).Num().String()
+	if len(digits) > lo { // rounded up to a power of 10
+		digits = digits[:lo]
+		e++
+	}
+	return strings.TrimRight(digits, "0"), e
+}
+
+// pow10 returns 10**n.
+func pow10(n int) *big.Rat {
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n))), nil)
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), p)
+	}
+	return new(big.Rat).SetInt(p)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// MarshalJSON implements the json.Marshaler interface. x is written as a
+// JSON number in the form of MarshalText, or as a string if
+// SetJSONQuote(true) was called. A nil x is written as null.
+func (x *Float) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	text, _ := x.MarshalText()
+	return marshalJSON(text, GetJSONQuote()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// JSON numbers and strings in the form accepted by UnmarshalText. null
+// leaves z unchanged, so a *Float decoded from null stays nil.
+func (z *Float) UnmarshalJSON(data []byte) error {
+	text, null, err := unmarshalJSON(data)
+	if err != nil || null {
+		return err
+	}
+	return z.UnmarshalText(text)
+}
+
+// exact returns x as a big.Float with the precision of x, or with the
+// precision of its bits if gmp keeps bits of x beyond GetPrec() in an
+// extra limb, so that it represents x exactly.
+func (x *Float) exact() *big.Float {
+	f := x.Big()
+	prec := x.GetPrec()
+	if n := f.MinPrec(); n > prec {
+		prec = n
+	}
+	return f.SetPrec(prec)
+}
+
+// oddMantExp returns x exactly as m × 2**exp, with m odd or, if x is 0,
+// m = exp = 0.
+func (x *Float) oddMantExp() (m *big.Int, exp int) {
+	f := x.exact()
+	if f.Sign() == 0 {
+		return new(big.Int), 0
+	}
+	prec := int(f.Prec())
+	exp = f.MantExp(f) - prec
+	m, _ = f.SetMantExp(f, prec).Int(nil)
+	tz := m.TrailingZeroBits()
+	return m.Rsh(m, tz), exp + int(tz)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding is a version and sign byte, the precision of x as a big-endian
+// uint32, and for nonzero x a big-endian int64 exponent e and an odd
+// mantissa m in 64-bit limbs, with |x| = m × 2**e. The mantissa includes
+// the bits of x beyond GetPrec() that gmp may keep in an extra limb.
+// MarshalBinary fails if the precision of x is above 1<<24.
+func (x *Float) MarshalBinary() ([]byte, error) {
+	prec := x.GetPrec()
+	if prec > maxBinaryPrec {
+		return nil, errors.New("gmp: Float.MarshalBinary: precision too large")
+	}
+	b := floatGobVersion << 1 // make space for sign bit
+	if x.Sgn() < 0 {
+		b |= 1
+	}
+	buf := make([]byte, 5, 13+int(prec+7)/8)
+	buf[0] = b
+	binary.BigEndian.PutUint32(buf[1:5], uint32(prec))
+
+	mant, exp := x.oddMantExp()
+	if mant.Sign() == 0 {
+		return buf, nil
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(exp))
+	m := new(Int).SetBig(mant.Abs(mant))
+	buf = appendLimbs(buf, m)
+	m.Clear()
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// z takes the precision of the encoded value, which must be in the range
+// [1, 1<<24].
+func (z *Float) UnmarshalBinary(buf []byte) error {
+	if len(buf) < 5 {
+		return errors.New("gmp: Float.UnmarshalBinary: buffer too small")
+	}
+	b := buf[0]
+	if b>>1 != floatGobVersion {
+		return fmt.Errorf("gmp: Float.UnmarshalBinary: encoding version %d not supported", b>>1)
+	}
+	prec := uint(binary.BigEndian.Uint32(buf[1:5]))
+	if prec == 0 || prec > maxBinaryPrec {
+		return fmt.Errorf("gmp: Float.UnmarshalBinary: invalid precision %d", prec)
+	}
+	buf = buf[5:]
+	if len(buf) == 0 {
+		z.SetPrec(prec)
+		z.SetInt64(0)
+		return nil
+	}
+	if len(buf) < 8 {
+		return errors.New("gmp: Float.UnmarshalBinary: buffer too small")
+	}
+	exp := int64(binary.BigEndian.Uint64(buf))
+	m := new(Int)
+	defer m.Clear()
+	if err := setLimbs(m, buf[8:]); err != nil {
+		return fmt.Errorf("gmp: Float.UnmarshalBinary: %v", err)
+	}
+	n := uint(m.BitLen())
+	if n == 0 || exp != int64(int32(exp)) {
+		return errors.New("gmp: Float.UnmarshalBinary: invalid mantissa or exponent")
+	}
+	// gmp keeps at most two limbs more than the precision; setExact below
+	// checks the exact bound once z has the precision.
+	if n > prec+128 {
+		return errors.New("gmp: Float.UnmarshalBinary: mantissa too large for precision")
+	}
+	f := new(big.Float).SetPrec(n).SetInt(m.Big())
+	f.SetMantExp(f, int(exp))
+	if b&1 != 0 {
+		f.Neg(f)
+	}
+	z.SetPrec(prec)
+	if !z.setExact(f) {
+		return errors.New("gmp: Float.UnmarshalBinary: mantissa too large for precision")
+	}
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (x *Float) GobEncode() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return x.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *Float) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		// Other side sent a nil or default value.
+		z.SetInt64(0)
+		return nil
+	}
+	return z.UnmarshalBinary(buf)
+}
@@ -1,3 +1,5 @@
+//go:build cgo
+
 // Copyright 2010 Utkan Güngördü.
 // Based on $(GOROOT)/misc/cgo/gmp/gmp.go
 // Released under the BSD-style license that can
@@ -27,11 +29,30 @@ void _mpz_div_2exp(mpz_ptr a, mpz_ptr b, unsigned long n) {
 int _mpz_sgn(mpz_ptr z) {
 	return mpz_sgn(z);
 }
+
+// The mpz struct is allocated with gmp's allocation function, not inside
+// the Int, so that it can be freed by a cleanup wherever the Int lives.
+mpz_ptr _mpz_new(void) {
+	void *(*alloc)(size_t);
+	mp_get_memory_functions(&alloc, NULL, NULL);
+	mpz_ptr z = alloc(sizeof(__mpz_struct));
+	mpz_init(z);
+	return z;
+}
+void _mpz_delete(mpz_ptr z) {
+	void (*dealloc)(void *, size_t);
+	mp_get_memory_functions(NULL, NULL, &dealloc);
+	mpz_clear(z);
+	dealloc(z, sizeof(__mpz_struct));
+}
 */
 import "C"
 
 import (
+	"bytes"
 	"os"
+	"runtime"
+	"slices"
 	"unsafe"
 )
 
@@ -42,35 +63,75 @@ var (
 
 // An Int represents a signed multi-precision integer.
 // The zero value for an Int represents the value 0.
+//
+// An Int must not be copied by value once it has been used; doing so
+// panics. Pass *Int instead.
 type Int struct {
-	ptr  C.mpz_ptr // pointer to underlying mpz so Int can be a reference
-	t    C.mpz_t   // mpz_t that needs to be initialized
-	init bool
+	noCopy  noCopy
+	addr    *Int      // of receiver, to detect copies by value
+	ptr     C.mpz_ptr // pointer to underlying mpz so Int can be a reference
+	ref     *Rat      // owner of *ptr if Int is a reference, kept alive
+	cleanup runtime.Cleanup
+	init    bool
 }
 
 // Int promises that the zero value is a 0, but in gmp
 // the zero value is a crash.  To bridge the gap, the
 // init bool says whether this is a valid gmp value.
-// doinit initializes z.t if it needs it.  This is not inherent
-// to FFI, just a mismatch between Go's convention of
+// doinit allocates and initializes z.ptr if it needs it.  This is not
+// inherent to FFI, just a mismatch between Go's convention of
 // making zero values useful and gmp's decision not to.
 //
-// Make z a reference to another mpz_t (not z.t) by directly assigning
-// z.ptr and leaving z.t uninitialized. See Rat.Denom() for an example.
+// Make z a reference to another mpz_t by directly assigning z.ptr and
+// z.ref instead. See Rat.Denom() for an example.
+//
+// doinit also registers a cleanup so the mpz is released once z becomes
+// unreachable, even if Clear is never called. The mpz lives in C memory,
+// so this works for Ints inside structs and slices too.
 func (z *Int) doinit() {
 	if z.init {
+		z.copyCheck()
 		return
 	}
 	z.init = true
-	z.ptr = &z.t[0]
-	C.mpz_init(z.ptr)
+	z.addr = z
+	z.ptr = C._mpz_new()
+	trackInit(unsafe.Pointer(z.ptr), "Int")
+	z.cleanup = runtime.AddCleanup(z, clearInt, z.ptr)
+	gcPressure()
+}
+
+// clearInt frees the mpz p allocated by doinit.
+func clearInt(p C.mpz_ptr) {
+	trackClear(unsafe.Pointer(p))
+	C._mpz_delete(p)
 }
 
-// Free the space occupied by the underlying gmp object.
+// copyCheck panics if z is an initialized Int that was copied by value.
+// The copy shares its limbs with the original, so using either would
+// corrupt the other.
+func (z *Int) copyCheck() {
+	if z.addr != z {
+		panic("gmp: illegal use of non-zero Int copied by value")
+	}
+}
+
+// Clear frees the space occupied by the underlying gmp object. Calling
+// Clear is optional: the space is also freed by a cleanup once z becomes
+// unreachable, but Clear releases it immediately. It is safe to call Clear
+// more than once, and z may be reused afterwards; it then holds the value 0.
 func (z *Int) Clear() {
-	if z.init {
-		C.mpz_clear(z.ptr)
+	if !z.init {
+		return
+	}
+	z.copyCheck()
+	// References (see Rat.Num) don't own their mpz_t.
+	if z.ref == nil {
+		z.cleanup.Stop()
+		clearInt(z.ptr)
 	}
+	z.ptr = nil
+	z.ref = nil
 	z.init = false
 }
 
@@ -83,8 +144,10 @@ func NewInt(x int64) *Int { return new(Int).SetInt64(x) }
 
 // Set sets z = x and returns z.
 func (z *Int) Set(x *Int) *Int {
+	x.doinit()
 	z.doinit()
 	C.mpz_set(z.ptr, x.ptr)
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -93,6 +156,7 @@ func (z *Int) Bytes() []byte {
 	b := make([]byte, (z.Len()+7)/8)
 	n := C.size_t(len(b))
 	C.mpz_export(unsafe.Pointer(&b[0]), &n, 1, 1, 1, 0, z.ptr)
+	runtime.KeepAlive(z)
 	return b[0:n]
 }
 
@@ -109,6 +173,127 @@ func (z *Int) SetBytes(b []byte) *Int {
 	return z
 }
 
+// FillBytes sets buf to the absolute value of z, storing it as a zero-extended
+// big-endian byte slice, and returns buf.
+//
+// If the absolute value of z doesn't fit in buf, FillBytes will panic.
+func (z *Int) FillBytes(buf []byte) []byte {
+	z.doinit()
+	for i := range buf {
+		buf[i] = 0
+	}
+	if z.Sign() == 0 {
+		return buf
+	}
+	n := (z.Len() + 7) / 8
+	if n > len(buf) {
+		panic("gmp: buffer too small to fit value")
+	}
+	C.mpz_export(unsafe.Pointer(&buf[len(buf)-n]), nil, 1, 1, 1, 0, z.ptr)
+	runtime.KeepAlive(z)
+	return buf
+}
+
+// checkWordFormat panics if order, size, endian and nails don't describe a
+// valid word format for mpz_import and mpz_export.
+func checkWordFormat(order, size, endian int, nails uint) {
+	switch {
+	case order != 1 && order != -1:
+		panic("gmp: word order must be 1 or -1")
+	case size <= 0:
+		panic("gmp: word size must be positive")
+	case endian < -1 || endian > 1:
+		panic("gmp: endianness must be 1, -1 or 0")
+	case nails >= uint(size)*8:
+		panic("gmp: nails must leave at least one bit per word")
+	}
+}
+
+// Export returns the absolute value of z as a sequence of words of size
+// bytes each, like mpz_export. The most significant word comes first if
+// order is 1 and last if order is -1. Within each word the most significant
+// byte comes first if endian is 1, last if endian is -1, and the host's byte
+// order is used if endian is 0. The most significant nails bits of each
+// word are unused and set to zero. The result is empty if z is zero.
+func (z *Int) Export(order, size, endian int, nails uint) []byte {
+	checkWordFormat(order, size, endian, nails)
+	z.doinit()
+	if z.Sign() == 0 {
+		return []byte{}
+	}
+	numb := uint(size)*8 - nails
+	count := (uint(z.Len()) + numb - 1) / numb
+	b := make([]byte, count*uint(size))
+	var n C.size_t
+	C.mpz_export(unsafe.Pointer(&b[0]), &n, C.int(order), C.size_t(size),
+		C.int(endian), C.size_t(nails), z.ptr)
+	runtime.KeepAlive(z)
+	return b[:int(n)*size]
+}
+
+// Import sets z to the non-negative value of the words in b, which are in
+// the format described by Export, and returns z. The nails bits of each
+// word are ignored. Import panics if len(b) is not a multiple of size.
+func (z *Int) Import(b []byte, order, size, endian int, nails uint) *Int {
+	checkWordFormat(order, size, endian, nails)
+	if len(b)%size != 0 {
+		panic("gmp: length of b must be a multiple of the word size")
+	}
+	z.doinit()
+	if len(b) == 0 {
+		C.mpz_set_ui(z.ptr, 0)
+		return z
+	}
+	C.mpz_import(z.ptr, C.size_t(len(b)/size), C.int(order), C.size_t(size),
+		C.int(endian), C.size_t(nails), unsafe.Pointer(&b[0]))
+	return z
+}
+
+// A Word represents a single limb of an Int.
+type Word uint
+
+// Word must have the same size as gmp's mp_limb_t for Bits to work.
+var (
+	_ [unsafe.Sizeof(Word(0)) - unsafe.Sizeof(C.mp_limb_t(0))]struct{}
+	_ [unsafe.Sizeof(C.mp_limb_t(0)) - unsafe.Sizeof(Word(0))]struct{}
+)
+
+// Bits provides raw access to z by returning its absolute value as a
+// little-endian Word slice. Unlike math/big, the limbs of z are owned by
+// gmp, so the result is a copy: it stays valid after z is modified, cleared
+// or collected, and writing to it doesn't change z.
+//
+// Bits is intended to support implementation of missing low-level Int
+// functionality outside this package; it should be avoided otherwise.
+func (z *Int) Bits() []Word {
+	z.doinit()
+	n := int(z.ptr._mp_size)
+	if n < 0 {
+		n = -n
+	}
+	if n == 0 {
+		return nil
+	}
+	abs := make([]Word, n)
+	copy(abs, unsafe.Slice((*Word)(unsafe.Pointer(z.ptr._mp_d)), n))
+	runtime.KeepAlive(z)
+	return abs
+}
+
+// SetBits sets z to the value of abs, interpreted as a little-endian Word
+// slice, and returns z. Unlike math/big, the words are copied into storage
+// owned by gmp, so abs may be reused afterwards.
+func (z *Int) SetBits(abs []Word) *Int {
+	z.doinit()
+	if len(abs) == 0 {
+		C.mpz_set_ui(z.ptr, 0)
+		return z
+	}
+	C.mpz_import(z.ptr, C.size_t(len(abs)), -1, C.size_t(unsafe.Sizeof(Word(0))),
+		0, 0, unsafe.Pointer(&abs[0]))
+	return z
+}
+
 // BitLen returns the length of the absolute value of z in bits.
 // The bit length of 0 is 0.
 func (x *Int) BitLen() int {
@@ -122,7 +307,9 @@ func (x *Int) BitLen() int {
 // Len returns the length of z in bits.  0 is considered to have length 1.
 func (z *Int) Len() int {
 	z.doinit()
-	return int(C.mpz_sizeinbase(z.ptr, 2))
+	r := int(C.mpz_sizeinbase(z.ptr, 2))
+	runtime.KeepAlive(z)
+	return r
 }
 
 // Int64 returns the int64 representation of x. If x cannot be represented
@@ -131,7 +318,10 @@ func (z *Int) Int64() int64 {
 	if !z.init {
 		return 0
 	}
-	return int64(C.mpz_get_si(z.ptr))
+	z.copyCheck()
+	r := int64(C.mpz_get_si(z.ptr))
+	runtime.KeepAlive(z)
+	return r
 }
 
 // SetInt64 sets z = x and returns z.
@@ -148,7 +338,10 @@ func (z *Int) Uint64() uint64 {
 	if !z.init {
 		return 0
 	}
-	return uint64(C.mpz_get_ui(z.ptr))
+	z.copyCheck()
+	r := uint64(C.mpz_get_ui(z.ptr))
+	runtime.KeepAlive(z)
+	return r
 }
 
 // SetUint64 sets z to x and returns z.
@@ -158,6 +351,15 @@ func (z *Int) SetUint64(x uint64) *Int {
 	return z
 }
 
+// SetFloat sets z to x truncated towards zero and returns z.
+func (z *Int) SetFloat(x *Float) *Int {
+	x.doinit()
+	z.doinit()
+	C.mpz_set_f(z.ptr, &x.i[0])
+	runtime.KeepAlive(x)
+	return z
+}
+
 // String returns the decimal representation of z.
 func (z *Int) String() string {
 	s, _ := z.StringBase(10)
@@ -168,27 +370,65 @@ func (z *Int) StringBase(base int) (string, error) {
 	if z == nil {
 		return "nil", nil
 	}
-	if base < 2 || base > 36 {
+	if base < 2 || base > MaxBase {
 		return "", os.ErrInvalid
 	}
 	z.doinit()
 	p := C.mpz_get_str(nil, C.int(base), z.ptr)
 	s := C.GoString(p)
-	C.free(unsafe.Pointer(p))
+	freeString(p)
+	runtime.KeepAlive(z)
 	return s, nil
 }
 
+// TextLen returns the length of x in the given base, including a minus
+// sign: the number of bytes StringBase and Append produce for x, or one
+// more than that. It is exact if base is a power of 2. TextLen panics if
+// the base is not in the range [2,MaxBase].
+func (x *Int) TextLen(base int) int {
+	if x == nil {
+		return len("nil")
+	}
+	if base < 2 || base > MaxBase {
+		panic("gmp: invalid base")
+	}
+	x.doinit()
+	n := int(C.mpz_sizeinbase(x.ptr, C.int(base)))
+	if x.Sign() < 0 {
+		n++
+	}
+	runtime.KeepAlive(x)
+	return n
+}
+
+// Append appends the string representation of x, as generated by
+// x.StringBase(base), to buf and returns the extended buffer. Unlike
+// StringBase it doesn't allocate if buf has room for TextLen(base)+1
+// more bytes. Append panics if the base is not in the range [2,MaxBase].
+func (x *Int) Append(buf []byte, base int) []byte {
+	if x == nil {
+		return append(buf, "nil"...)
+	}
+	// mpz_get_str writes the digits and a terminating NUL in place.
+	n := x.TextLen(base) + 1
+	i := len(buf)
+	buf = slices.Grow(buf, n)[:i+n]
+	C.mpz_get_str((*C.char)(unsafe.Pointer(&buf[i])), C.int(base), x.ptr)
+	runtime.KeepAlive(x)
+	return buf[:i+bytes.IndexByte(buf[i:], 0)]
+}
+
 // SetString sets z to the value of s, interpreted in the given base,
 // and returns z and a boolean indicating success. If SetString fails, the
 // value of z is undefined but the returned value is nil.
 
-// The base argument must be 0 or a value from 2 through 36. If the base is 0,
+// The base argument must be 0 or a value from 2 through MaxBase. If the base is 0,
 // the string prefix determines the actual conversion base. A prefix of “0x” or
 // “0X” selects base 16; the “0” prefix selects base 8, and a “0b” or “0B”
 // prefix selects base 2. Otherwise the selected base is 10.
 func (z *Int) SetString(s string, base int) (*Int, bool) {
 	z.doinit()
-	if base < 0 || base == 1 || base > 36 {
+	if base < 0 || base == 1 || base > MaxBase {
 		return nil, false
 	}
 
@@ -202,8 +442,9 @@ func (z *Int) SetString(s string, base int) (*Int, bool) {
 		s = s[1:]
 	}
 
-	// attempting to set "0x" and "0b" should return nil like math/big
-	if len(s) == 2 {
+	// attempting to set "0x" and "0b" should return nil like math/big;
+	// in bases above 33, they are valid numbers.
+	if base == 0 && len(s) == 2 {
 		switch s {
 		case "0x", "0X", "0b", "0B":
 			return nil, false
@@ -213,6 +454,7 @@ func (z *Int) SetString(s string, base int) (*Int, bool) {
 	p := C.CString(s)
 	defer C.free(unsafe.Pointer(p))
 	if C.mpz_set_str(z.ptr, p, C.int(base)) < 0 {
+		runtime.KeepAlive(z)
 		return nil, false
 	}
 	return z, true
@@ -228,6 +470,8 @@ func (z *Int) Add(x, y *Int) *Int {
 	y.doinit()
 	z.doinit()
 	C.mpz_add(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -237,6 +481,8 @@ func (z *Int) Sub(x, y *Int) *Int {
 	y.doinit()
 	z.doinit()
 	C.mpz_sub(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -246,6 +492,8 @@ func (z *Int) Mul(x, y *Int) *Int {
 	y.doinit()
 	z.doinit()
 	C.mpz_mul(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -313,6 +561,8 @@ func (z *Int) Quo(x, y *Int) *Int {
 	y.doinit()
 	z.doinit()
 	C.mpz_tdiv_q(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -324,6 +574,8 @@ func (z *Int) Rem(x, y *Int) *Int {
 	y.doinit()
 	z.doinit()
 	C.mpz_tdiv_r(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -345,6 +597,8 @@ func (z *Int) QuoRem(x, y, r *Int) (*Int, *Int) {
 	r.doinit()
 	z.doinit()
 	C.mpz_tdiv_qr(z.ptr, r.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z, r
 }
 
@@ -439,6 +693,9 @@ func (z *Int) Exp(x, y, m *Int) *Int {
 		m.doinit()
 		C.mpz_powm(z.ptr, x.ptr, y.ptr, m.ptr)
 	}
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	runtime.KeepAlive(m)
 	return z
 }
 
@@ -447,6 +704,7 @@ func (z *Int) Sqrt(x *Int) *Int {
 	z.doinit()
 	x.doinit()
 	C.mpz_sqrt(z.ptr, x.ptr)
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -455,6 +713,7 @@ func (z *Int) Neg(x *Int) *Int {
 	x.doinit()
 	z.doinit()
 	C.mpz_neg(z.ptr, x.ptr)
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -463,6 +722,7 @@ func (z *Int) Abs(x *Int) *Int {
 	x.doinit()
 	z.doinit()
 	C.mpz_abs(z.ptr, x.ptr)
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -475,6 +735,7 @@ func (z *Int) Lsh(x *Int, s uint) *Int {
 	x.doinit()
 	z.doinit()
 	C._mpz_mul_2exp(z.ptr, x.ptr, C.ulong(s))
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -483,6 +744,7 @@ func (z *Int) Rsh(x *Int, s uint) *Int {
 	x.doinit()
 	z.doinit()
 	C._mpz_div_2exp(z.ptr, x.ptr, C.ulong(s))
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -492,6 +754,8 @@ func (z *Int) And(x, y *Int) *Int {
 	x.doinit()
 	y.doinit()
 	C.mpz_and(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -512,6 +776,8 @@ func (z *Int) Or(x, y *Int) *Int {
 	x.doinit()
 	y.doinit()
 	C.mpz_ior(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -521,6 +787,8 @@ func (z *Int) Xor(x, y *Int) *Int {
 	x.doinit()
 	y.doinit()
 	C.mpz_xor(z.ptr, x.ptr, y.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return z
 }
 
@@ -529,6 +797,7 @@ func (z *Int) Not(x *Int) *Int {
 	z.doinit()
 	x.doinit()
 	C.mpz_com(z.ptr, x.ptr)
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -536,7 +805,9 @@ func (z *Int) Not(x *Int) *Int {
 // returns (x>>i)&1. The bit index i must be >= 0.
 func (x *Int) Bit(i int) uint {
 	x.doinit()
-	return uint(C.mpz_tstbit(x.ptr, C.mp_bitcnt_t(i)))
+	r := uint(C.mpz_tstbit(x.ptr, C.mp_bitcnt_t(i)))
+	runtime.KeepAlive(x)
+	return r
 }
 
 // SetBit sets z to x, with x's i'th bit set to b (0 or 1).
@@ -573,6 +844,8 @@ func (z *Int) ModInverse(g, p *Int) *Int {
 	p.doinit()
 	z.doinit()
 	C.mpz_invert(z.ptr, g.ptr, p.ptr)
+	runtime.KeepAlive(g)
+	runtime.KeepAlive(p)
 	return z
 }
 
@@ -603,6 +876,10 @@ func (z *Int) GCD(x, y, a, b *Int) *Int {
 	a.doinit()
 	b.doinit()
 	C.mpz_gcdext(z.ptr, x_ptr, y_ptr, a.ptr, b.ptr)
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
 	return z
 }
 
@@ -611,7 +888,9 @@ func (z *Int) GCD(x, y, a, b *Int) *Int {
 // If it returns false, z is not prime.
 func (z *Int) ProbablyPrime(n int) bool {
 	z.doinit()
-	return int(C.mpz_probab_prime_p(z.ptr, C.int(n))) > 0
+	r := int(C.mpz_probab_prime_p(z.ptr, C.int(n))) > 0
+	runtime.KeepAlive(z)
+	return r
 }
 
 /*
@@ -626,7 +905,9 @@ func (z *Int) ProbablyPrime(n int) bool {
 //
 func (z *Int) Sign() int {
 	z.doinit()
-	return int(C._mpz_sgn(z.ptr))
+	r := int(C._mpz_sgn(z.ptr))
+	runtime.KeepAlive(z)
+	return r
 }
 
 // Cmp compares x and y. The result is
@@ -638,7 +919,10 @@ func (z *Int) Sign() int {
 func (x *Int) Cmp(y *Int) int {
 	x.doinit()
 	y.doinit()
-	switch cmp := int(C.mpz_cmp(x.ptr, y.ptr)); {
+	cmp := int(C.mpz_cmp(x.ptr, y.ptr))
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	switch {
 	case cmp < 0:
 		return -1
 	case cmp == 0:
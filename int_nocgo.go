@@ -0,0 +1,850 @@
+//go:build !cgo
+
+package gmp
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/bits"
+	"os"
+	"slices"
+	"sync"
+	"unsafe"
+)
+
+var (
+	intZero = NewInt(0)
+	intOne  = NewInt(1)
+)
+
+// An Int represents a signed multi-precision integer.
+// The zero value for an Int represents the value 0.
+//
+// An Int must not be copied by value once it has been used; doing so
+// panics. Pass *Int instead.
+type Int struct {
+	noCopy noCopy
+	addr   *Int     // of receiver, to detect copies by value
+	ptr    *big.Int // pointer to underlying value so Int can be a reference
+	t      big.Int  // value of z unless z is a reference
+	init   bool
+}
+
+// doinit points z.ptr at z.t the first time z is used, mirroring the cgo
+// implementation so that Rat.Num and Rat.Denom can return references.
+func (z *Int) doinit() {
+	if z.init {
+		z.copyCheck()
+		return
+	}
+	z.init = true
+	z.addr = z
+	z.ptr = &z.t
+	trackInit(unsafe.Pointer(z), "Int")
+	trackCollect(unsafe.Pointer(z))
+}
+
+// copyCheck panics if z is an initialized Int that was copied by value.
+// The copy shares its words with the original, so using either would
+// corrupt the other.
+func (z *Int) copyCheck() {
+	if z.addr != z {
+		panic("gmp: illegal use of non-zero Int copied by value")
+	}
+}
+
+// Clear releases the words held by z. Calling Clear is optional: without
+// cgo the memory is managed by the garbage collector. It is safe to call
+// Clear more than once, and z may be reused afterwards; it then holds the
+// value 0.
+func (z *Int) Clear() {
+	if !z.init {
+		return
+	}
+	z.copyCheck()
+	// References (see Rat.Num) don't own their value.
+	if z.ptr == &z.t {
+		z.t = big.Int{}
+		trackClear(unsafe.Pointer(z))
+	}
+	z.ptr = nil
+	z.init = false
+}
+
+/*
+ * assigning and converting
+ */
+
+// NewInt returns a new Int initialized to x.
+func NewInt(x int64) *Int { return new(Int).SetInt64(x) }
+
+// Set sets z = x and returns z.
+func (z *Int) Set(x *Int) *Int {
+	x.doinit()
+	z.doinit()
+	z.ptr.Set(x.ptr)
+	return z
+}
+
+// Bytes returns z's representation as a big-endian byte array.
+func (z *Int) Bytes() []byte {
+	z.doinit()
+	return z.ptr.Bytes()
+}
+
+// SetBytes interprets b as the bytes of a big-endian integer
+// and sets z to that value.
+func (z *Int) SetBytes(b []byte) *Int {
+	z.doinit()
+	z.ptr.SetBytes(b)
+	return z
+}
+
+// FillBytes sets buf to the absolute value of z, storing it as a zero-extended
+// big-endian byte slice, and returns buf.
+//
+// If the absolute value of z doesn't fit in buf, FillBytes will panic.
+func (z *Int) FillBytes(buf []byte) []byte {
+	z.doinit()
+	if (z.ptr.BitLen()+7)/8 > len(buf) {
+		panic("gmp: buffer too small to fit value")
+	}
+	return z.ptr.FillBytes(buf)
+}
+
+// checkWordFormat panics if order, size, endian and nails don't describe a
+// valid word format for Import and Export.
+func checkWordFormat(order, size, endian int, nails uint) {
+	switch {
+	case order != 1 && order != -1:
+		panic("gmp: word order must be 1 or -1")
+	case size <= 0:
+		panic("gmp: word size must be positive")
+	case endian < -1 || endian > 1:
+		panic("gmp: endianness must be 1, -1 or 0")
+	case nails >= uint(size)*8:
+		panic("gmp: nails must leave at least one bit per word")
+	}
+}
+
+// wordByte returns the index of the i'th least significant byte of a word
+// of size bytes stored with the given endianness.
+func wordByte(i, size, endian int) int {
+	if endian == 0 {
+		if binary.NativeEndian.Uint16([]byte{1, 0}) == 1 {
+			endian = -1
+		} else {
+			endian = 1
+		}
+	}
+	if endian == 1 {
+		return size - 1 - i
+	}
+	return i
+}
+
+// wordOffset returns the offset in a buffer of count words of size bytes
+// of the i'th least significant word, stored in the given order.
+func wordOffset(i, count, size, order int) int {
+	if order == 1 {
+		return (count - 1 - i) * size
+	}
+	return i * size
+}
+
+// Export returns the absolute value of z as a sequence of words of size
+// bytes each, like mpz_export. The most significant word comes first if
+// order is 1 and last if order is -1. Within each word the most significant
+// byte comes first if endian is 1, last if endian is -1, and the host's byte
+// order is used if endian is 0. The most significant nails bits of each
+// word are unused and set to zero. The result is empty if z is zero.
+func (z *Int) Export(order, size, endian int, nails uint) []byte {
+	checkWordFormat(order, size, endian, nails)
+	z.doinit()
+	if z.Sign() == 0 {
+		return []byte{}
+	}
+	numb := size*8 - int(nails)
+	bitLen := z.ptr.BitLen()
+	count := (bitLen + numb - 1) / numb
+	b := make([]byte, count*size)
+	abs := new(big.Int).Abs(z.ptr)
+	for i := 0; i < count; i++ {
+		off := wordOffset(i, count, size, order)
+		for j := 0; j < numb; j++ {
+			if abs.Bit(i*numb+j) != 0 {
+				b[off+wordByte(j/8, size, endian)] |= 1 << (j % 8)
+			}
+		}
+	}
+	return b
+}
+
+// Import sets z to the non-negative value of the words in b, which are in
+// the format described by Export, and returns z. The nails bits of each
+// word are ignored. Import panics if len(b) is not a multiple of size.
+func (z *Int) Import(b []byte, order, size, endian int, nails uint) *Int {
+	checkWordFormat(order, size, endian, nails)
+	if len(b)%size != 0 {
+		panic("gmp: length of b must be a multiple of the word size")
+	}
+	z.doinit()
+	numb := size*8 - int(nails)
+	count := len(b) / size
+	x := new(big.Int)
+	for i := 0; i < count; i++ {
+		off := wordOffset(i, count, size, order)
+		for j := 0; j < numb; j++ {
+			if b[off+wordByte(j/8, size, endian)]&(1<<(j%8)) != 0 {
+				x.SetBit(x, i*numb+j, 1)
+			}
+		}
+	}
+	z.ptr.Set(x)
+	return z
+}
+
+// A Word represents a single limb of an Int.
+type Word uint
+
+// Bits provides raw access to z by returning its absolute value as a
+// little-endian Word slice. Unlike math/big, the result is a copy: it stays
+// valid after z is modified, cleared or collected, and writing to it
+// doesn't change z.
+//
+// Bits is intended to support implementation of missing low-level Int
+// functionality outside this package; it should be avoided otherwise.
+func (z *Int) Bits() []Word {
+	z.doinit()
+	words := z.ptr.Bits()
+	if len(words) == 0 {
+		return nil
+	}
+	abs := make([]Word, len(words))
+	for i, w := range words {
+		abs[i] = Word(w)
+	}
+	return abs
+}
+
+// SetBits sets z to the value of abs, interpreted as a little-endian Word
+// slice, and returns z. Unlike math/big, the words are copied, so abs may
+// be reused afterwards.
+func (z *Int) SetBits(abs []Word) *Int {
+	z.doinit()
+	words := make([]big.Word, len(abs))
+	for i, w := range abs {
+		words[i] = big.Word(w)
+	}
+	z.ptr.SetBits(words)
+	return z
+}
+
+// BitLen returns the length of the absolute value of z in bits.
+// The bit length of 0 is 0.
+func (x *Int) BitLen() int {
+	x.doinit()
+	return x.ptr.BitLen()
+}
+
+// Len returns the length of z in bits.  0 is considered to have length 1.
+func (z *Int) Len() int {
+	z.doinit()
+	if n := z.ptr.BitLen(); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Int64 returns the int64 representation of x. If x cannot be represented
+// in an int64, the result is undefined.
+func (z *Int) Int64() int64 {
+	if !z.init {
+		return 0
+	}
+	z.copyCheck()
+	return z.ptr.Int64()
+}
+
+// SetInt64 sets z = x and returns z.
+func (z *Int) SetInt64(x int64) *Int {
+	z.doinit()
+	z.ptr.SetInt64(x)
+	return z
+}
+
+// Uint64 returns the uint64 representation of x. If x cannot be
+// represented in an uint64, the result is undefined.
+func (z *Int) Uint64() uint64 {
+	if !z.init {
+		return 0
+	}
+	z.copyCheck()
+	return z.ptr.Uint64()
+}
+
+// SetUint64 sets z to x and returns z.
+func (z *Int) SetUint64(x uint64) *Int {
+	z.doinit()
+	z.ptr.SetUint64(x)
+	return z
+}
+
+// SetFloat sets z to x truncated towards zero and returns z.
+func (z *Int) SetFloat(x *Float) *Int {
+	x.doinit()
+	z.doinit()
+	x.i.Int(z.ptr)
+	return z
+}
+
+// String returns the decimal representation of z.
+func (z *Int) String() string {
+	s, _ := z.StringBase(10)
+	return s
+}
+
+func (z *Int) StringBase(base int) (string, error) {
+	if z == nil {
+		return "nil", nil
+	}
+	if base < 2 || base > MaxBase {
+		return "", os.ErrInvalid
+	}
+	z.doinit()
+	return text(z.ptr, base), nil
+}
+
+// TextLen returns the length of x in the given base, including a minus
+// sign: the number of bytes StringBase and Append produce for x, or one
+// more than that. It is exact if base is a power of 2. TextLen panics if
+// the base is not in the range [2,MaxBase].
+func (x *Int) TextLen(base int) int {
+	if x == nil {
+		return len("nil")
+	}
+	if base < 2 || base > MaxBase {
+		panic("gmp: invalid base")
+	}
+	// Like mpz_sizeinbase, estimate from the bit length: x < 2**nbits.
+	nbits := x.Len()
+	var n int
+	if base&(base-1) == 0 {
+		lg := bits.TrailingZeros(uint(base))
+		n = (nbits + lg - 1) / lg
+	} else {
+		n = int(float64(nbits)/math.Log2(float64(base))) + 1
+	}
+	if x.Sign() < 0 {
+		n++
+	}
+	return n
+}
+
+// Append appends the string representation of x, as generated by
+// x.StringBase(base), to buf and returns the extended buffer. Unlike
+// StringBase it doesn't allocate if buf has room for TextLen(base)+1
+// more bytes and |x| < 2**4096. Append panics if the base is not in the
+// range [2,MaxBase].
+func (x *Int) Append(buf []byte, base int) []byte {
+	if x == nil {
+		return append(buf, "nil"...)
+	}
+	if base < 2 || base > MaxBase {
+		panic("gmp: invalid base")
+	}
+	x.doinit()
+	i := len(buf)
+	if x.ptr.BitLen() > maxAppendBits {
+		// math/big converts large values faster, but allocates.
+		buf = x.ptr.Append(buf, base)
+		if base > 36 {
+			swapCase(buf[i:])
+		}
+		return buf
+	}
+	n := x.TextLen(base)
+	buf = slices.Grow(buf, n)[:i+n]
+	j := i + putDigits(buf[i:], x.ptr.Bits(), base)
+	if x.Sign() < 0 {
+		j--
+		buf[j] = '-'
+	}
+	return buf[:i+copy(buf[i:], buf[j:])]
+}
+
+// maxAppendBits is the bit length of the largest values Append converts
+// itself. The conversion is quadratic in the number of words.
+const maxAppendBits = 4096
+
+// appendScratch holds copies of the words of an Int for putDigits to
+// divide, so that Append doesn't allocate.
+var appendScratch = sync.Pool{New: func() any { return new([]big.Word) }}
+
+// putDigits writes the digits of abs in base, with gmp's digits, to the
+// end of dst and returns the index of the first one.
+func putDigits(dst []byte, abs []big.Word, base int) int {
+	digits := gmpDigits(base)
+	j := len(dst)
+	if len(abs) == 0 {
+		j--
+		dst[j] = '0'
+		return j
+	}
+
+	// Divide a copy of abs by bb, the largest power of base that fits in a
+	// Word, and write the nd digits of each remainder.
+	b := uint(base)
+	bb, nd := b, 1
+	for bb <= math.MaxUint/b {
+		bb *= b
+		nd++
+	}
+	p := appendScratch.Get().(*[]big.Word)
+	q := append((*p)[:0], abs...)
+	for len(q) > 0 {
+		var r uint
+		for k := len(q) - 1; k >= 0; k-- {
+			var d uint
+			d, r = bits.Div(r, uint(q[k]), bb)
+			q[k] = big.Word(d)
+		}
+		for len(q) > 0 && q[len(q)-1] == 0 {
+			q = q[:len(q)-1]
+		}
+		// Leading zeros are only written for the lower remainders.
+		for k := 0; k < nd && (len(q) > 0 || r != 0); k++ {
+			j--
+			dst[j] = digits[r%b]
+			r /= b
+		}
+	}
+	*p = q[:0]
+	appendScratch.Put(p)
+	return j
+}
+
+// text returns x in base with gmp's digits. For bases above 36, math/big
+// uses a-z for the digit values 10 to 35 and A-Z for 36 to 61, and gmp the
+// other way around.
+func text(x *big.Int, base int) string {
+	if base <= 36 {
+		return x.Text(base)
+	}
+	b := x.Append(nil, base)
+	swapCase(b)
+	return string(b)
+}
+
+// SetString sets z to the value of s, interpreted in the given base,
+// and returns z and a boolean indicating success. If SetString fails, the
+// value of z is undefined but the returned value is nil.
+
+// The base argument must be 0 or a value from 2 through MaxBase. If the base is 0,
+// the string prefix determines the actual conversion base. A prefix of “0x” or
+// “0X” selects base 16; the “0” prefix selects base 8, and a “0b” or “0B”
+// prefix selects base 2. Otherwise the selected base is 10.
+func (z *Int) SetString(s string, base int) (*Int, bool) {
+	z.doinit()
+	if base < 0 || base == 1 || base > MaxBase {
+		return nil, false
+	}
+
+	if len(s) == 0 {
+		return nil, false
+	}
+
+	// positive signs should be ignored
+	if s[0] == '+' {
+		s = s[1:]
+	}
+
+	if !setIntString(z.ptr, s, base) {
+		return nil, false
+	}
+	return z, true
+}
+
+// setIntString sets z to the value of s like mpz_set_str: white space is
+// ignored, s may start with a minus sign, and for base 0 the prefix selects
+// the base. It reports whether s was valid.
+func setIntString(z *big.Int, s string, base int) bool {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ' ', '\t', '\n', '\v', '\f', '\r':
+		default:
+			digits = append(digits, c)
+		}
+	}
+
+	neg := len(digits) > 0 && digits[0] == '-'
+	if neg {
+		digits = digits[1:]
+	}
+	if base == 0 {
+		base = 10
+		if len(digits) > 1 && digits[0] == '0' {
+			switch digits[1] {
+			case 'x', 'X':
+				base, digits = 16, digits[2:]
+			case 'b', 'B':
+				base, digits = 2, digits[2:]
+			default:
+				base, digits = 8, digits[1:]
+			}
+		}
+	}
+	if len(digits) == 0 {
+		return false
+	}
+	for _, c := range digits {
+		if digitValue(rune(c), base) >= base {
+			return false
+		}
+	}
+	if base > 36 {
+		swapCase(digits)
+	}
+	if _, ok := z.SetString(string(digits), base); !ok {
+		return false
+	}
+	if neg {
+		z.Neg(z)
+	}
+	return true
+}
+
+/*
+ * arithmetic
+ */
+
+// Add sets z = x + y and returns z.
+func (z *Int) Add(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Add(x.ptr, y.ptr)
+	return z
+}
+
+// Sub sets z = x - y and returns z.
+func (z *Int) Sub(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Sub(x.ptr, y.ptr)
+	return z
+}
+
+// Mul sets z = x * y and returns z.
+func (z *Int) Mul(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Mul(x.ptr, y.ptr)
+	return z
+}
+
+// MulRange sets z to the product of all integers
+// in the range [a, b] inclusively and returns z.
+// If a > b (empty range), the result is 1.
+func (z *Int) MulRange(a, b int64) *Int {
+	z.doinit()
+	z.ptr.MulRange(a, b)
+	return z
+}
+
+// Quo sets z to the quotient x/y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Quo implements truncated division (like Go).
+func (z *Int) Quo(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Quo(x.ptr, y.ptr)
+	return z
+}
+
+// Rem sets z to the remainder x%y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Rem implements truncated modulus (like Go); see QuoRem for more details.
+func (z *Int) Rem(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Rem(x.ptr, y.ptr)
+	return z
+}
+
+// QuoRem sets z to the quotient x/y and r to the remainder x%y
+// and returns the pair (z, r) for y != 0.
+// If y == 0, a division-by-zero run-time panic occurs.
+//
+// QuoRem implements T-division and modulus (like Go):
+//
+//	q = x/y      with the result truncated to zero
+//	r = x - y*q
+//
+// (See Daan Leijen, “Division and Modulus for Computer Scientists”.)
+// See DivMod for Euclidean division and modulus (unlike Go).
+func (z *Int) QuoRem(x, y, r *Int) (*Int, *Int) {
+	x.doinit()
+	y.doinit()
+	r.doinit()
+	z.doinit()
+	z.ptr.QuoRem(x.ptr, y.ptr, r.ptr)
+	return z, r
+}
+
+// Div sets z to the quotient x/y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Div implements Euclidean division (unlike Go); see DivMod for more details.
+func (z *Int) Div(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Div(x.ptr, y.ptr)
+	return z
+}
+
+// Mod sets z to the modulus x%y for y != 0 and returns z.
+// If y == 0, a division-by-zero run-time panic occurs.
+// Mod implements Euclidean modulus (unlike Go); see DivMod for more details.
+func (z *Int) Mod(x, y *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	z.ptr.Mod(x.ptr, y.ptr)
+	return z
+}
+
+// DivMod sets z to the quotient x div y and m to the modulus x mod y
+// and returns the pair (z, m) for y != 0.
+// If y == 0, a division-by-zero run-time panic occurs.
+//
+// DivMod implements Euclidean division and modulus (unlike Go); see
+// math/big.Int.DivMod for details.
+// See QuoRem for T-division and modulus (like Go).
+func (z *Int) DivMod(x, y, m *Int) (*Int, *Int) {
+	x.doinit()
+	y.doinit()
+	m.doinit()
+	z.doinit()
+	z.ptr.DivMod(x.ptr, y.ptr, m.ptr)
+	return z, m
+}
+
+// Exp sets z = x^y % m and returns z. If m != nil, negative exponents are
+// allowed if x^-1 mod m exists. If the inverse doesn't exist then a
+// division-by-zero run-time panic occurs.
+//
+// If m == nil, Exp sets z = x^y for positive y and 1 for negative y.
+func (z *Int) Exp(x, y, m *Int) *Int {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	if m == nil || m.Cmp(intZero) == 0 {
+		if y.Sign() == -1 {
+			z := NewInt(1)
+			return z
+		}
+		z.ptr.Exp(x.ptr, y.ptr, nil)
+	} else {
+		m.doinit()
+		if z.ptr.Exp(x.ptr, y.ptr, m.ptr) == nil {
+			panic("division by zero")
+		}
+	}
+	return z
+}
+
+// Sqrt sets z = floor(sqrt(x)) and returns z.
+func (z *Int) Sqrt(x *Int) *Int {
+	z.doinit()
+	x.doinit()
+	z.ptr.Sqrt(x.ptr)
+	return z
+}
+
+// Neg sets z = -x and returns z.
+func (z *Int) Neg(x *Int) *Int {
+	x.doinit()
+	z.doinit()
+	z.ptr.Neg(x.ptr)
+	return z
+}
+
+// Abs sets z to the absolute value of x and returns z.
+func (z *Int) Abs(x *Int) *Int {
+	x.doinit()
+	z.doinit()
+	z.ptr.Abs(x.ptr)
+	return z
+}
+
+/*
+ * logic and bit fiddling
+ */
+
+// Lsh sets z = x << s and returns z.
+func (z *Int) Lsh(x *Int, s uint) *Int {
+	x.doinit()
+	z.doinit()
+	z.ptr.Lsh(x.ptr, s)
+	return z
+}
+
+// Rsh sets z = x >> s and returns z.
+func (z *Int) Rsh(x *Int, s uint) *Int {
+	x.doinit()
+	z.doinit()
+	z.ptr.Rsh(x.ptr, s)
+	return z
+}
+
+// And sets z = x & y and returns z.
+func (z *Int) And(x, y *Int) *Int {
+	z.doinit()
+	x.doinit()
+	y.doinit()
+	z.ptr.And(x.ptr, y.ptr)
+	return z
+}
+
+// AndNot sets z = x &^ y and returns z.
+func (z *Int) AndNot(x, y *Int) *Int {
+	z.doinit()
+	x.doinit()
+	y.doinit()
+	z.ptr.AndNot(x.ptr, y.ptr)
+	return z
+}
+
+// Or sets z = x | y and returns z.
+func (z *Int) Or(x, y *Int) *Int {
+	z.doinit()
+	x.doinit()
+	y.doinit()
+	z.ptr.Or(x.ptr, y.ptr)
+	return z
+}
+
+// Xor sets z = x ^ y and returns z.
+func (z *Int) Xor(x, y *Int) *Int {
+	z.doinit()
+	x.doinit()
+	y.doinit()
+	z.ptr.Xor(x.ptr, y.ptr)
+	return z
+}
+
+// Not sets z = ^x and returns z.
+func (z *Int) Not(x *Int) *Int {
+	z.doinit()
+	x.doinit()
+	z.ptr.Not(x.ptr)
+	return z
+}
+
+// Bit returns the value of the i'th bit of x. That is, it
+// returns (x>>i)&1. The bit index i must be >= 0.
+func (x *Int) Bit(i int) uint {
+	x.doinit()
+	return x.ptr.Bit(i)
+}
+
+// SetBit sets z to x, with x's i'th bit set to b (0 or 1).
+// That is, if bit is 1 SetBit sets z = x | (1 << i);
+// if bit is 0 it sets z = x &^ (1 << i). If bit is not 0 or 1,
+// SetBit will panic.
+func (z *Int) SetBit(x *Int, i int, b uint) *Int {
+	z.doinit()
+	x.doinit()
+
+	if i < 0 {
+		panic("negative bit index")
+	}
+	if b > 1 {
+		panic("set bit is not 0 or 1")
+	}
+	z.ptr.SetBit(x.ptr, i, b)
+	return z
+}
+
+/*
+ * number theory
+ */
+
+// ModInverse sets z to the multiplicative inverse of g in the group ℤ/pℤ
+// (where p is a prime) and returns z.
+func (z *Int) ModInverse(g, p *Int) *Int {
+	g.doinit()
+	p.doinit()
+	z.doinit()
+	z.ptr.ModInverse(g.ptr, p.ptr)
+	return z
+}
+
+// GCD sets z to the greatest common divisor of a and b, which must be positive
+// numbers, and returns z. If x and y are not nil, GCD sets x and y such that
+// z = a*x + b*y. If either a or b is not positive, GCD sets z = x = y = 0.
+func (z *Int) GCD(x, y, a, b *Int) *Int {
+	z.doinit()
+
+	// Compatibility with math/big
+	if a.Cmp(intZero) <= 0 || b.Cmp(intZero) <= 0 {
+		z.Set(intZero)
+		return z
+	}
+
+	// allow for nil x and y
+	var xPtr, yPtr *big.Int
+	if x != nil {
+		x.doinit()
+		xPtr = x.ptr
+	}
+	if y != nil {
+		y.doinit()
+		yPtr = y.ptr
+	}
+
+	a.doinit()
+	b.doinit()
+	z.ptr.GCD(xPtr, yPtr, a.ptr, b.ptr)
+	return z
+}
+
+// ProbablyPrime performs n Miller-Rabin tests to check whether z is prime.
+// If it returns true, z is prime with probability 1 - 1/4^n.
+// If it returns false, z is not prime.
+func (z *Int) ProbablyPrime(n int) bool {
+	z.doinit()
+	return z.ptr.ProbablyPrime(n)
+}
+
+/*
+ * comparisons
+ */
+
+// Sign returns:
+//
+//	-1 if x <  0
+//	 0 if x == 0
+//	+1 if x >  0
+func (z *Int) Sign() int {
+	z.doinit()
+	return z.ptr.Sign()
+}
+
+// Cmp compares x and y. The result is
+//
+//	-1 if x <  y
+//	 0 if x == y
+//	+1 if x >  y
+func (x *Int) Cmp(y *Int) int {
+	x.doinit()
+	y.doinit()
+	return x.ptr.Cmp(y.ptr)
+}
@@ -6,8 +6,19 @@ package gmp
 
 import (
 	"bytes"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/gob"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 	"testing/quick"
 )
@@ -50,7 +61,7 @@ func TestSetZ(t *testing.T) {
 		var z Int
 		z.Set(a.z)
 		if (&z).Cmp(a.z) != 0 {
-			t.Errorf("got z = %v; want %v", z, a.z)
+			t.Errorf("got z = %v; want %v", &z, a.z)
 		}
 	}
 }
@@ -66,7 +77,7 @@ func TestAbsZ(t *testing.T) {
 			e.Sub(&zero, &e)
 		}
 		if z.Cmp(&e) != 0 {
-			t.Errorf("got z = %v; want %v", z, e)
+			t.Errorf("got z = %v; want %v", &z, &e)
 		}
 	}
 }
@@ -1164,3 +1175,1008 @@ func TestModInverse(t *testing.T) {
 		}
 	}
 }
+
+func TestIntClear(t *testing.T) {
+	z := NewInt(42)
+	z.Clear()
+	z.Clear() // clearing twice must be harmless
+	if s := z.String(); s != "0" {
+		t.Errorf("got %s after Clear; want 0", s)
+	}
+	z.SetInt64(7)
+	if s := z.String(); s != "7" {
+		t.Errorf("got %s after reuse; want 7", s)
+	}
+	z.Clear()
+}
+
+func TestIntInStruct(t *testing.T) {
+	// Ints that are struct fields or slice elements don't start their
+	// allocation, which must not get in the way of releasing them.
+	p := new(struct{ a, b Int })
+	p.b.SetInt64(5)
+	s := make([]Int, 3)
+	s[1].SetInt64(6)
+	runtime.GC()
+	if p.b.Int64() != 5 || s[1].Int64() != 6 {
+		t.Errorf("got %s and %s; want 5 and 6", &p.b, &s[1])
+	}
+	p.a.Add(&p.b, &s[1])
+	if p.a.Int64() != 11 {
+		t.Errorf("got %s; want 11", &p.a)
+	}
+	// A zero value is a valid source too.
+	if p.a.Set(&s[2]).Sign() != 0 {
+		t.Errorf("Set(zero value) = %s; want 0", &p.a)
+	}
+	p.b.Clear()
+	s[1].Clear()
+}
+
+// copyValue copies *src into *dst the way an accidental struct assignment
+// would, without tripping vet's copylocks check.
+func copyValue(dst, src interface{}) {
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(src).Elem())
+}
+
+func expectCopyPanic(t *testing.T, name string, f func()) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s on a copy did not panic", name)
+		}
+	}()
+	f()
+}
+
+func TestIntCopyCheck(t *testing.T) {
+	var zero, y Int
+	copyValue(&y, &zero) // copying the zero value is fine
+	y.SetInt64(1)
+
+	x := NewInt(42)
+	var z Int
+	copyValue(&z, x)
+	expectCopyPanic(t, "Add", func() { z.Add(&z, intOne) })
+	expectCopyPanic(t, "Set", func() { new(Int).Set(&z) })
+	expectCopyPanic(t, "Int64", func() { z.Int64() })
+	expectCopyPanic(t, "Clear", func() { z.Clear() })
+	if s := x.String(); s != "42" {
+		t.Errorf("original changed to %s; want 42", s)
+	}
+}
+
+var exportTests = []struct {
+	x                   string
+	order, size, endian int
+	nails               uint
+	out                 string // hex
+}{
+	{"0", 1, 1, 1, 0, ""},
+	{"0x0102030405", 1, 1, 1, 0, "0102030405"},
+	{"0x0102030405", -1, 1, 1, 0, "0504030201"},
+	{"-0x0102030405", 1, 1, 1, 0, "0102030405"},
+	{"0x0102030405", 1, 2, 1, 0, "000102030405"},
+	{"0x0102030405", 1, 2, -1, 0, "010003020504"},
+	{"0x0102030405", -1, 2, 1, 0, "040502030001"},
+	{"0x0102030405", -1, 4, -1, 0, "0504030201000000"},
+	{"0xff", 1, 1, 1, 1, "017f"},
+	{"0x7fff", -1, 1, 1, 1, "7f7f01"},
+}
+
+func TestExportImport(t *testing.T) {
+	for i, test := range exportTests {
+		x, _ := new(Int).SetString(test.x, 0)
+		b := x.Export(test.order, test.size, test.endian, test.nails)
+		if got := hex.EncodeToString(b); got != test.out {
+			t.Errorf("#%d Export got %s want %s", i, got, test.out)
+		}
+		y := new(Int).Import(b, test.order, test.size, test.endian, test.nails)
+		if y.Cmp(new(Int).Abs(x)) != 0 {
+			t.Errorf("#%d Import got %s want %s", i, y, new(Int).Abs(x))
+		}
+	}
+}
+
+func TestFillBytes(t *testing.T) {
+	x, _ := new(Int).SetString("-0x010203", 0)
+	buf := []byte{9, 9, 9, 9, 9}
+	if got := x.FillBytes(buf); !bytes.Equal(got, []byte{0, 0, 1, 2, 3}) {
+		t.Errorf("FillBytes got %v", got)
+	}
+	if got := new(Int).FillBytes(buf); !bytes.Equal(got, make([]byte, 5)) {
+		t.Errorf("FillBytes(0) got %v", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("FillBytes into a short buffer did not panic")
+		}
+	}()
+	x.FillBytes(make([]byte, 2))
+}
+
+func TestBits(t *testing.T) {
+	for _, abs := range [][]Word{
+		nil,
+		{1},
+		{0, 1},
+		{^Word(0), ^Word(0), 7},
+	} {
+		z := new(Int).SetBits(abs)
+		got := z.Bits()
+		if len(got) != len(abs) {
+			t.Errorf("SetBits(%v).Bits() = %v", abs, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != abs[i] {
+				t.Errorf("SetBits(%v).Bits() = %v", abs, got)
+				break
+			}
+		}
+		if z.Neg(z).Bits() == nil && len(abs) != 0 {
+			t.Errorf("Bits of negative value is empty")
+		}
+	}
+}
+
+func TestBitsOutlivesInt(t *testing.T) {
+	abs := new(Int).Lsh(NewInt(0x1234), 200).Bits()
+	want := append([]Word(nil), abs...)
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+		for j := 0; j < 100; j++ {
+			new(Int).Lsh(NewInt(-1), 200)
+		}
+	}
+	if !slices.Equal(abs, want) {
+		t.Errorf("Bits changed after the Int was collected: got %v, want %v", abs, want)
+	}
+	z := NewInt(7)
+	abs = z.Bits()
+	z.SetInt64(9)
+	if abs[0] != 7 {
+		t.Errorf("Bits changed after the Int was modified: got %v", abs)
+	}
+}
+
+var formatTests = []struct {
+	input  string
+	format string
+	output string
+}{
+	{"<nil>", "%x", "<nil>"},
+	{"<nil>", "%#x", "<nil>"},
+	{"<nil>", "%#y", "%!y(gmp.Int=<nil>)"},
+
+	{"10", "%b", "1010"},
+	{"10", "%o", "12"},
+	{"10", "%d", "10"},
+	{"10", "%v", "10"},
+	{"10", "%x", "a"},
+	{"10", "%X", "A"},
+	{"-10", "%X", "-A"},
+	{"10", "%y", "%!y(gmp.Int=10)"},
+	{"-10", "%y", "%!y(gmp.Int=-10)"},
+
+	{"10", "%#b", "0b1010"},
+	{"10", "%#o", "012"},
+	{"10", "%O", "0o12"},
+	{"-10", "%#b", "-0b1010"},
+	{"-10", "%#o", "-012"},
+	{"-10", "%O", "-0o12"},
+	{"10", "%#d", "10"},
+	{"10", "%#v", "10"},
+	{"10", "%#x", "0xa"},
+	{"10", "%#X", "0XA"},
+	{"-10", "%#X", "-0XA"},
+	{"10", "%#y", "%!y(gmp.Int=10)"},
+	{"-10", "%#y", "%!y(gmp.Int=-10)"},
+
+	{"1234", "%d", "1234"},
+	{"1234", "%3d", "1234"},
+	{"1234", "%4d", "1234"},
+	{"-1234", "%d", "-1234"},
+	{"1234", "% 5d", " 1234"},
+	{"1234", "%+5d", "+1234"},
+	{"1234", "%-5d", "1234 "},
+	{"1234", "%x", "4d2"},
+	{"1234", "%X", "4D2"},
+	{"-1234", "%3x", "-4d2"},
+	{"-1234", "%4x", "-4d2"},
+	{"-1234", "%5x", " -4d2"},
+	{"-1234", "%-5x", "-4d2 "},
+	{"1234", "%03d", "1234"},
+	{"1234", "%04d", "1234"},
+	{"1234", "%05d", "01234"},
+	{"1234", "%06d", "001234"},
+	{"-1234", "%06d", "-01234"},
+	{"1234", "%+06d", "+01234"},
+	{"1234", "% 06d", " 01234"},
+	{"1234", "%-6d", "1234  "},
+	{"1234", "%-06d", "1234  "},
+	{"-1234", "%-06d", "-1234 "},
+
+	{"1234", "%.3d", "1234"},
+	{"1234", "%.4d", "1234"},
+	{"1234", "%.5d", "01234"},
+	{"1234", "%.6d", "001234"},
+	{"-1234", "%.3d", "-1234"},
+	{"-1234", "%.4d", "-1234"},
+	{"-1234", "%.5d", "-01234"},
+	{"-1234", "%.6d", "-001234"},
+
+	{"1234", "%8.3d", "    1234"},
+	{"1234", "%8.4d", "    1234"},
+	{"1234", "%8.5d", "   01234"},
+	{"1234", "%8.6d", "  001234"},
+	{"-1234", "%8.3d", "   -1234"},
+	{"-1234", "%8.4d", "   -1234"},
+	{"-1234", "%8.5d", "  -01234"},
+	{"-1234", "%8.6d", " -001234"},
+
+	{"1234", "%+8.3d", "   +1234"},
+	{"1234", "%+8.4d", "   +1234"},
+	{"1234", "%+8.5d", "  +01234"},
+	{"1234", "%+8.6d", " +001234"},
+	{"-1234", "%+8.3d", "   -1234"},
+	{"-1234", "%+8.4d", "   -1234"},
+	{"-1234", "%+8.5d", "  -01234"},
+	{"-1234", "%+8.6d", " -001234"},
+
+	{"1234", "% 8.3d", "    1234"},
+	{"1234", "% 8.4d", "    1234"},
+	{"1234", "% 8.5d", "   01234"},
+	{"1234", "% 8.6d", "  001234"},
+	{"-1234", "% 8.3d", "   -1234"},
+	{"-1234", "% 8.4d", "   -1234"},
+	{"-1234", "% 8.5d", "  -01234"},
+	{"-1234", "% 8.6d", " -001234"},
+
+	{"1234", "%.3x", "4d2"},
+	{"1234", "%.4x", "04d2"},
+	{"1234", "%.5x", "004d2"},
+	{"1234", "%.6x", "0004d2"},
+	{"-1234", "%.3x", "-4d2"},
+	{"-1234", "%.4x", "-04d2"},
+	{"-1234", "%.5x", "-004d2"},
+	{"-1234", "%.6x", "-0004d2"},
+
+	{"1234", "%8.3x", "     4d2"},
+	{"1234", "%8.4x", "    04d2"},
+	{"1234", "%8.5x", "   004d2"},
+	{"1234", "%8.6x", "  0004d2"},
+	{"-1234", "%8.3x", "    -4d2"},
+	{"-1234", "%8.4x", "   -04d2"},
+	{"-1234", "%8.5x", "  -004d2"},
+	{"-1234", "%8.6x", " -0004d2"},
+
+	{"1234", "%+8.3x", "    +4d2"},
+	{"1234", "%+8.4x", "   +04d2"},
+	{"1234", "%+8.5x", "  +004d2"},
+	{"1234", "%+8.6x", " +0004d2"},
+	{"-1234", "%+8.3x", "    -4d2"},
+	{"-1234", "%+8.4x", "   -04d2"},
+	{"-1234", "%+8.5x", "  -004d2"},
+	{"-1234", "%+8.6x", " -0004d2"},
+
+	{"1234", "% 8.3x", "     4d2"},
+	{"1234", "% 8.4x", "    04d2"},
+	{"1234", "% 8.5x", "   004d2"},
+	{"1234", "% 8.6x", "  0004d2"},
+	{"1234", "% 8.7x", " 00004d2"},
+	{"1234", "% 8.8x", " 000004d2"},
+	{"-1234", "% 8.3x", "    -4d2"},
+	{"-1234", "% 8.4x", "   -04d2"},
+	{"-1234", "% 8.5x", "  -004d2"},
+	{"-1234", "% 8.6x", " -0004d2"},
+	{"-1234", "% 8.7x", "-00004d2"},
+	{"-1234", "% 8.8x", "-000004d2"},
+
+	{"1234", "%-8.3d", "1234    "},
+	{"1234", "%-8.4d", "1234    "},
+	{"1234", "%-8.5d", "01234   "},
+	{"1234", "%-8.6d", "001234  "},
+	{"1234", "%-8.7d", "0001234 "},
+	{"1234", "%-8.8d", "00001234"},
+	{"-1234", "%-8.3d", "-1234   "},
+	{"-1234", "%-8.4d", "-1234   "},
+	{"-1234", "%-8.5d", "-01234  "},
+	{"-1234", "%-8.6d", "-001234 "},
+	{"-1234", "%-8.7d", "-0001234"},
+	{"-1234", "%-8.8d", "-00001234"},
+
+	{"16777215", "%b", "111111111111111111111111"}, // 2**24 - 1
+
+	{"0", "%.d", ""},
+	{"0", "%.0d", ""},
+	{"0", "%3.d", ""},
+}
+
+func TestFormat(t *testing.T) {
+	testFormat(t)
+}
+
+func testFormat(t testing.TB) {
+	for i, test := range formatTests {
+		var x *Int
+		if test.input != "<nil>" {
+			var ok bool
+			x, ok = new(Int).SetString(test.input, 0)
+			if !ok {
+				t.Errorf("#%d failed reading input %s", i, test.input)
+			}
+		}
+		output := fmt.Sprintf(test.format, x)
+		if output != test.output {
+			t.Errorf("#%d got %q; want %q, {%q, %q, %q}", i, output, test.output, test.input, test.format, test.output)
+		}
+	}
+}
+
+type scanTest struct {
+	input     string
+	format    string
+	output    string
+	remaining int
+}
+
+var scanTests = []scanTest{
+	{"1010", "%b", "10", 0},
+	{"0b1010", "%v", "10", 0},
+	{"12", "%o", "10", 0},
+	{"012", "%v", "10", 0},
+	{"10", "%d", "10", 0},
+	{"10", "%v", "10", 0},
+	{"a", "%x", "10", 0},
+	{"0xa", "%v", "10", 0},
+	{"A", "%X", "10", 0},
+	{"-A", "%X", "-10", 0},
+	{"+0b1011001", "%v", "89", 0},
+	{"0xA", "%v", "10", 0},
+	{"0 ", "%v", "0", 1},
+	{"2+3", "%v", "2", 2},
+	{"0XABC 12", "%v", "2748", 3},
+
+	{"10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ffffffffffffffffffffffffffffffff00000000000022222223333333333444444444", "%x", "72999049881955123498258745691204661198291656115976958889267080286388402675338838184094604981077942396458276955120179409196748346461468914795561487752253275293347599221664790586512596660792869956", 0},
+	{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff33377fffffffffffffffffffffffffffffffffffffffffffff0000000000022222eee1", "%x", "1167984798111281975972139931059274579172666497855631342228273284582214442805421410945513679697247078343332431249286160621687557589604464869034163736183926240549918956767671325412748661204059352801", 0},
+	{"5c0d52f451aec609b15da8e5e5626c4eaa88723bdeac9d25ca9b961269400410ca208a16af9c2fb07d7a11c7772cba02c22f9711078d51a3797eb18e691295293284d988e349fa6deba46b25a4ecd9f715", "%x", "419981998319789881681348172155240145539175961318447822049735313481433836043208347786919222066492311384432264836938599791362288343314139526391998172436831830624710446410781662672086936222288181013", 0},
+	{"92fcad4b5c0d52f451aec609b15da8e5e5626c4eaa88723bdeac9d25ca9b961269400410ca208a16af9c2fb07d799c32fe2f3cc5422f9711078d51a3797eb18e691295293284d8f5e69caf6decddfe1df6", "%x", "670619546945481998414061201992255225716434798957375727890607516800039934374391281275121813279544891602026798031004764406015624866771554937391445093144221697436880587924204655403711377861305572854", 0},
+	{"10000000000000000000000200000000000000000000003000000000000000000000040000000000000000000000500000000000000000000006", "%d", "10000000000000000000000200000000000000000000003000000000000000000000040000000000000000000000500000000000000000000006", 0},
+}
+
+func TestScan(t *testing.T) {
+	var buf bytes.Buffer
+	for i, test := range scanTests {
+		x := new(Int)
+		buf.Reset()
+		buf.WriteString(test.input)
+		if _, err := fmt.Fscanf(&buf, test.format, x); err != nil {
+			t.Errorf("#%d error: %s", i, err)
+		}
+		if x.String() != test.output {
+			t.Errorf("#%d got %s; want %s", i, x.String(), test.output)
+		}
+		if buf.Len() != test.remaining {
+			t.Errorf("#%d got %d bytes remaining; want %d", i, buf.Len(), test.remaining)
+		}
+	}
+}
+
+func TestIntMarshalText(t *testing.T) {
+	for _, test := range []string{"0", "1", "-1", "1234567890123456789012345678901234567890"} {
+		x, _ := new(Int).SetString(test, 10)
+		text, err := x.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(text) != test {
+			t.Errorf("MarshalText(%s) = %s", test, text)
+		}
+		var y Int
+		if err := y.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if y.Cmp(x) != 0 {
+			t.Errorf("UnmarshalText(%s) = %s", text, &y)
+		}
+	}
+
+	var z Int
+	if err := z.UnmarshalText([]byte("0x1f")); err != nil || z.Int64() != 31 {
+		t.Errorf("UnmarshalText(0x1f) = %s, %v; want 31", &z, err)
+	}
+	if err := z.UnmarshalText([]byte("1.5")); err == nil {
+		t.Error("UnmarshalText(1.5) succeeded")
+	}
+}
+
+func TestIntJSON(t *testing.T) {
+	type record struct {
+		X *Int
+		Y *Int `json:",omitempty"`
+	}
+
+	x, _ := new(Int).SetString("123456789012345678901234567890", 10)
+	for _, test := range []struct {
+		quote bool
+		want  string
+	}{
+		{false, `{"X":123456789012345678901234567890}`},
+		{true, `{"X":"123456789012345678901234567890"}`},
+	} {
+		old := SetJSONQuote(test.quote)
+		b, err := json.Marshal(record{X: x})
+		SetJSONQuote(old)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("quote %v: got %s; want %s", test.quote, b, test.want)
+		}
+		var r record
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Fatal(err)
+		}
+		if r.X.Cmp(x) != 0 || r.Y != nil {
+			t.Errorf("quote %v: %s decoded to %v", test.quote, b, r)
+		}
+	}
+
+	r := record{X: NewInt(1), Y: NewInt(2)}
+	if err := json.Unmarshal([]byte(`{"X":null,"Y":" -7"}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.X != nil || r.Y.Int64() != -7 {
+		t.Errorf("got %v; want {<nil> -7}", r)
+	}
+	if b, _ := json.Marshal(record{}); string(b) != `{"X":null}` {
+		t.Errorf("got %s; want {\"X\":null}", b)
+	}
+
+	for _, input := range []string{`1.5`, `"x"`, `true`, `"1`} {
+		if err := new(Int).UnmarshalJSON([]byte(input)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) succeeded", input)
+		}
+	}
+}
+
+func TestIntBinary(t *testing.T) {
+	for _, test := range []string{"0", "1", "-1", "18446744073709551616", "-123456789012345678901234567890"} {
+		x, _ := new(Int).SetString(test, 10)
+		b, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 1 + (x.BitLen()+63)/64*8; len(b) != want {
+			t.Errorf("%s: encoding has %d bytes; want %d", test, len(b), want)
+		}
+		var y Int
+		if err := y.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if y.Cmp(x) != 0 {
+			t.Errorf("%s: decoded %s", test, &y)
+		}
+	}
+
+	for _, b := range [][]byte{nil, {4}, {2, 1}} {
+		if err := new(Int).UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%x) succeeded", b)
+		}
+	}
+}
+
+func TestIntGob(t *testing.T) {
+	type record struct {
+		X, Y *Int
+	}
+	x, _ := new(Int).SetString("-98765432109876543210", 10)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record{X: x}); err != nil {
+		t.Fatal(err)
+	}
+	var r record
+	if err := gob.NewDecoder(&buf).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.X.Cmp(x) != 0 || r.Y != nil {
+		t.Errorf("got %v; want {%s <nil>}", r, x)
+	}
+}
+
+func TestIntRaw(t *testing.T) {
+	var buf bytes.Buffer
+	for _, test := range []struct {
+		x   string
+		raw string
+	}{
+		{"0", "00000000"},
+		{"1", "0000000101"},
+		{"-258", "fffffffe0102"},
+		{"18446744073709551616", "00000009010000000000000000"},
+		{"-18446744073709551615", "fffffff8ffffffffffffffff"},
+	} {
+		x, _ := new(Int).SetString(test.x, 10)
+		buf.Reset()
+		n, err := x.WriteRaw(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(buf.Bytes()); got != test.raw || n != buf.Len() {
+			t.Errorf("WriteRaw(%s) = %s, %d; want %s", test.x, got, n, test.raw)
+		}
+		var y Int
+		if n, err := y.ReadRaw(&buf); err != nil || n != len(test.raw)/2 {
+			t.Errorf("ReadRaw(%s) = %d, %v", test.raw, n, err)
+		}
+		if y.Cmp(x) != 0 {
+			t.Errorf("ReadRaw(%s) = %s; want %s", test.raw, &y, test.x)
+		}
+	}
+
+	// Records are read one after the other, with io.EOF at the end.
+	buf.Reset()
+	NewInt(5).WriteRaw(&buf)
+	NewInt(-6).WriteRaw(&buf)
+	var x, y, z Int
+	if _, err := x.ReadRaw(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := y.ReadRaw(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if x.Int64() != 5 || y.Int64() != -6 {
+		t.Errorf("read %s, %s; want 5, -6", &x, &y)
+	}
+	if _, err := z.ReadRaw(&buf); err != io.EOF {
+		t.Errorf("ReadRaw at end returned %v; want io.EOF", err)
+	}
+	if _, err := z.ReadRaw(bytes.NewReader([]byte{0, 0, 0, 2, 1})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadRaw of truncated record returned %v; want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestIntAppend(t *testing.T) {
+	large := new(Int).Lsh(NewInt(3), 300)
+	huge := new(Int).Lsh(NewInt(7), 5000)
+	for _, x := range []*Int{NewInt(0), NewInt(1), NewInt(-1), NewInt(99), NewInt(100), NewInt(-1000), large, new(Int).Neg(large), huge} {
+		for base := 2; base <= MaxBase; base++ {
+			want, _ := x.StringBase(base)
+			if got := string(x.Append([]byte("x="), base)); got != "x="+want {
+				t.Errorf("Append(%s, %d) = %s; want x=%s", x, base, got, want)
+			}
+			if n := x.TextLen(base); n != len(want) && n != len(want)+1 {
+				t.Errorf("TextLen(%s, %d) = %d; want %d", x, base, n, len(want))
+			} else if base&(base-1) == 0 && n != len(want) {
+				t.Errorf("TextLen(%s, %d) = %d; want exactly %d", x, base, n, len(want))
+			}
+		}
+	}
+	var x *Int
+	if got := string(x.Append(nil, 10)); got != "nil" || x.TextLen(10) != 3 {
+		t.Errorf("nil Append = %s, TextLen = %d", got, x.TextLen(10))
+	}
+}
+
+func TestIntAppendAllocs(t *testing.T) {
+	x := new(Int).Lsh(NewInt(3), 1000)
+	buf := make([]byte, 0, x.TextLen(10)+1)
+	if n := testing.AllocsPerRun(100, func() { buf = x.Append(buf[:0], 10) }); n != 0 {
+		t.Errorf("Append allocates %v times; want 0", n)
+	}
+}
+
+func BenchmarkIntAppend(b *testing.B) {
+	x := new(Int).Lsh(NewInt(3), 1000)
+	buf := make([]byte, 0, x.TextLen(10)+1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = x.Append(buf[:0], 10)
+	}
+}
+
+func BenchmarkIntString(b *testing.B) {
+	x := new(Int).Lsh(NewInt(3), 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = x.String()
+	}
+}
+
+func TestIntCBOR(t *testing.T) {
+	for _, test := range []struct {
+		x    string
+		cbor string
+	}{
+		// From RFC 8949, Appendix A.
+		{"0", "00"},
+		{"23", "17"},
+		{"24", "1818"},
+		{"1000000", "1a000f4240"},
+		{"-1", "20"},
+		{"-1000", "3903e7"},
+		{"18446744073709551615", "1bffffffffffffffff"},
+		{"18446744073709551616", "c249010000000000000000"},
+		{"-18446744073709551616", "3bffffffffffffffff"},
+		{"-18446744073709551617", "c349010000000000000000"},
+	} {
+		x, _ := new(Int).SetString(test.x, 10)
+		data, err := x.MarshalCBOR()
+		if err != nil || hex.EncodeToString(data) != test.cbor {
+			t.Errorf("MarshalCBOR(%s) = %x, %v; want %s", test.x, data, err, test.cbor)
+		}
+		var y Int
+		if err := y.UnmarshalCBOR(data); err != nil || y.Cmp(x) != 0 {
+			t.Errorf("UnmarshalCBOR(%s) = %s, %v; want %s", test.cbor, &y, err, test.x)
+		}
+	}
+
+	// Bignums needn't be minimal.
+	for _, test := range []struct {
+		cbor string
+		x    int64
+	}{
+		{"c240", 0},
+		{"c2420001", 1},
+		{"c340", -1},
+		{"1b0000000000000005", 5},
+	} {
+		data, _ := hex.DecodeString(test.cbor)
+		var x Int
+		if err := x.UnmarshalCBOR(data); err != nil || x.Int64() != test.x {
+			t.Errorf("UnmarshalCBOR(%s) = %s, %v; want %d", test.cbor, &x, err, test.x)
+		}
+	}
+
+	for _, s := range []string{"", "18", "c2", "c24201", "c201", "c440", "6161", "0000", "5f4100ff", "f4"} {
+		data, _ := hex.DecodeString(s)
+		if err := new(Int).UnmarshalCBOR(data); err == nil {
+			t.Errorf("UnmarshalCBOR(%s) succeeded", s)
+		}
+	}
+
+	x := NewInt(7)
+	if err := x.UnmarshalCBOR([]byte{0xf6}); err != nil || x.Int64() != 7 {
+		t.Errorf("UnmarshalCBOR(null) = %s, %v; want 7 unchanged", x, err)
+	}
+	if data, _ := (*Int)(nil).MarshalCBOR(); !bytes.Equal(data, []byte{0xf6}) {
+		t.Errorf("MarshalCBOR(nil) = %x; want f6", data)
+	}
+}
+
+func TestIntDER(t *testing.T) {
+	for _, test := range []struct {
+		x   string
+		der string
+	}{
+		{"0", "020100"},
+		{"1", "020101"},
+		{"127", "02017f"},
+		{"128", "02020080"},
+		{"256", "02020100"},
+		{"-1", "0201ff"},
+		{"-128", "020180"},
+		{"-129", "0202ff7f"},
+		{"-256", "0202ff00"},
+		{"-257", "0202feff"},
+		{"18446744073709551616", "0209010000000000000000"},
+		{"-18446744073709551616", "0209ff0000000000000000"},
+	} {
+		x, _ := new(Int).SetString(test.x, 10)
+		data, err := x.MarshalDER()
+		if err != nil || hex.EncodeToString(data) != test.der {
+			t.Errorf("MarshalDER(%s) = %x, %v; want %s", test.x, data, err, test.der)
+		}
+		var y Int
+		if err := y.UnmarshalDER(data); err != nil || y.Cmp(x) != 0 {
+			t.Errorf("UnmarshalDER(%s) = %s, %v; want %s", test.der, &y, err, test.x)
+		}
+	}
+
+	// Long lengths.
+	x := new(Int).Lsh(NewInt(1), 1016)
+	x.Neg(x)
+	data, _ := x.MarshalDER()
+	if want := "028180" + "ff" + strings.Repeat("00", 127); hex.EncodeToString(data) != want {
+		t.Errorf("MarshalDER(-2**1016) = %x; want %s", data, want)
+	}
+	var y Int
+	if err := y.UnmarshalDER(data); err != nil || y.Cmp(x) != 0 {
+		t.Errorf("UnmarshalDER(%x) = %s, %v; want %s", data, &y, err, x)
+	}
+
+	for _, s := range []string{
+		"", "02", "0200", "0201", "030100", "02010000",
+		"02020001", "02020080ff", "0202ff80", "0202ffff",
+		"0280", "02810100", "0282007f" + strings.Repeat("00", 127), "02850000000001",
+	} {
+		data, _ := hex.DecodeString(s)
+		if err := new(Int).UnmarshalDER(data); err == nil {
+			t.Errorf("UnmarshalDER(%s) succeeded", s)
+		}
+	}
+}
+
+func TestIntRawValue(t *testing.T) {
+	type gmpKey struct {
+		N, E asn1.RawValue
+	}
+	type bigKey struct {
+		N *big.Int
+		E int
+	}
+	n, _ := new(Int).SetString("-c7f1ab7e9d3e3bd2ab5e64b31a3c5d8e2b9e5e7d1c20", 16)
+	data, err := asn1.Marshal(gmpKey{n.RawValue(), NewInt(65537).RawValue()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := asn1.Marshal(bigKey{n.Big(), 65537})
+	if !bytes.Equal(data, want) {
+		t.Errorf("asn1.Marshal = %x; want %x", data, want)
+	}
+
+	var k gmpKey
+	if _, err := asn1.Unmarshal(want, &k); err != nil {
+		t.Fatal(err)
+	}
+	var m, e Int
+	if _, err := m.SetRawValue(k.N); err != nil || m.Cmp(n) != 0 {
+		t.Errorf("SetRawValue(N) = %s, %v; want %s", &m, err, n)
+	}
+	if _, err := e.SetRawValue(k.E); err != nil || e.Int64() != 65537 {
+		t.Errorf("SetRawValue(E) = %s, %v; want 65537", &e, err)
+	}
+	if _, err := e.SetRawValue(asn1.RawValue{Tag: asn1.TagOctetString, Bytes: []byte{1}}); err == nil {
+		t.Error("SetRawValue(OCTET STRING) succeeded")
+	}
+	if _, err := e.SetRawValue(asn1.RawValue{Tag: asn1.TagInteger, Bytes: []byte{0, 1}}); err == nil {
+		t.Error("SetRawValue of non-minimal INTEGER succeeded")
+	}
+}
+
+func TestIntMPInt(t *testing.T) {
+	// From RFC 4251, section 5.
+	for _, test := range []struct {
+		x     string
+		mpint string
+	}{
+		{"0", "00000000"},
+		{"9a378f9b2e332a7", "0000000809a378f9b2e332a7"},
+		{"80", "000000020080"},
+		{"-1234", "00000002edcc"},
+		{"-deadbeef", "00000005ff21524111"},
+	} {
+		x, _ := new(Int).SetString(test.x, 16)
+		var buf bytes.Buffer
+		n, err := x.WriteMPInt(&buf)
+		if got := hex.EncodeToString(buf.Bytes()); err != nil || got != test.mpint || n != len(test.mpint)/2 {
+			t.Errorf("WriteMPInt(%s) = %s, %d, %v; want %s", test.x, got, n, err, test.mpint)
+		}
+		var y Int
+		if n, err := y.ReadMPInt(&buf); err != nil || n != len(test.mpint)/2 || y.Cmp(x) != 0 {
+			t.Errorf("ReadMPInt(%s) = %s, %d, %v; want %s", test.mpint, &y, n, err, test.x)
+		}
+	}
+
+	for _, test := range []struct {
+		mpint string
+		err   error
+	}{
+		{"", io.EOF},
+		{"000000", io.ErrUnexpectedEOF},
+		{"0000000201", io.ErrUnexpectedEOF},
+		{"000000020001", nil},
+		{"00000002ff80", nil},
+	} {
+		data, _ := hex.DecodeString(test.mpint)
+		_, err := new(Int).ReadMPInt(bytes.NewReader(data))
+		if err == nil || test.err != nil && err != test.err {
+			t.Errorf("ReadMPInt(%s) returned %v; want %v", test.mpint, err, test.err)
+		}
+	}
+}
+
+func TestIntMPI(t *testing.T) {
+	for _, test := range []struct {
+		x   string
+		mpi string
+	}{
+		// From RFC 4880, section 3.2.
+		{"1", "000101"},
+		{"511", "000901ff"},
+		{"0", "0000"},
+		{"65535", "0010ffff"},
+	} {
+		x, _ := new(Int).SetString(test.x, 10)
+		var buf bytes.Buffer
+		n, err := x.WriteMPI(&buf)
+		if got := hex.EncodeToString(buf.Bytes()); err != nil || got != test.mpi || n != len(test.mpi)/2 {
+			t.Errorf("WriteMPI(%s) = %s, %d, %v; want %s", test.x, got, n, err, test.mpi)
+		}
+		var y Int
+		if n, err := y.ReadMPI(&buf); err != nil || n != len(test.mpi)/2 || y.Cmp(x) != 0 {
+			t.Errorf("ReadMPI(%s) = %s, %d, %v; want %s", test.mpi, &y, n, err, test.x)
+		}
+	}
+
+	if _, err := NewInt(-1).WriteMPI(io.Discard); err == nil {
+		t.Error("WriteMPI(-1) succeeded")
+	}
+	if _, err := new(Int).Lsh(NewInt(1), 65535).WriteMPI(io.Discard); err == nil {
+		t.Error("WriteMPI(2**65535) succeeded")
+	}
+
+	for _, test := range []struct {
+		mpi string
+		err error
+	}{
+		{"", io.EOF},
+		{"00", io.ErrUnexpectedEOF},
+		{"000901", io.ErrUnexpectedEOF},
+		{"000a01ff", nil},
+		{"000800ff", nil},
+	} {
+		data, _ := hex.DecodeString(test.mpi)
+		_, err := new(Int).ReadMPI(bytes.NewReader(data))
+		if err == nil || test.err != nil && err != test.err {
+			t.Errorf("ReadMPI(%s) returned %v; want %v", test.mpi, err, test.err)
+		}
+	}
+}
+
+func TestSignedBytes(t *testing.T) {
+	for _, test := range []struct {
+		x      int64
+		signed string
+		fill   string
+	}{
+		{0, "00", "00000000"},
+		{1, "01", "00000001"},
+		{127, "7f", "0000007f"},
+		{128, "0080", "00000080"},
+		{255, "00ff", "000000ff"},
+		{65536, "010000", "00010000"},
+		{-1, "ff", "ffffffff"},
+		{-128, "80", "ffffff80"},
+		{-129, "ff7f", "ffffff7f"},
+		{-65536, "ff0000", "ffff0000"},
+		{-2147483648, "80000000", "80000000"},
+	} {
+		x := NewInt(test.x)
+		if got := hex.EncodeToString(x.SignedBytes()); got != test.signed {
+			t.Errorf("SignedBytes(%d) = %s; want %s", test.x, got, test.signed)
+		}
+		if got := hex.EncodeToString(x.FillSignedBytes(make([]byte, 4))); got != test.fill {
+			t.Errorf("FillSignedBytes(%d) = %s; want %s", test.x, got, test.fill)
+		}
+		for _, s := range []string{test.signed, test.fill} {
+			b, _ := hex.DecodeString(s)
+			if y := new(Int).SetSignedBytes(b); y.Int64() != test.x {
+				t.Errorf("SetSignedBytes(%s) = %s; want %d", s, y, test.x)
+			}
+		}
+	}
+	if x := NewInt(5).SetSignedBytes(nil); x.Sign() != 0 {
+		t.Errorf("SetSignedBytes(nil) = %s; want 0", x)
+	}
+
+	// Compare with math/big: a negative value is b - 2**(8*len(b)).
+	f := func(b []byte) bool {
+		x := new(Int).SetSignedBytes(b)
+		want := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			want.Sub(want, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+		}
+		return x.Big().Cmp(want) == 0 && new(Int).SetSignedBytes(x.SignedBytes()).Cmp(x) == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("FillSignedBytes(128, 1 byte) didn't panic")
+		}
+	}()
+	NewInt(128).FillSignedBytes(make([]byte, 1))
+}
+
+func TestIntVarint(t *testing.T) {
+	for _, test := range []struct {
+		x       string
+		uvarint string
+		varint  string
+	}{
+		{"0", "00", "00"},
+		{"1", "01", "02"},
+		{"63", "3f", "7e"},
+		{"64", "40", "8001"},
+		{"127", "7f", "fe01"},
+		{"128", "8001", "8002"},
+		{"300", "ac02", "d804"},
+		{"18446744073709551615", "ffffffffffffffffff01", "feffffffffffffffff03"},
+		{"18446744073709551616", "80808080808080808002", "80808080808080808004"},
+		{"-1", "", "01"},
+		{"-64", "", "7f"},
+		{"-65", "", "8101"},
+		{"-18446744073709551616", "", "ffffffffffffffffff03"},
+	} {
+		x, _ := new(Int).SetString(test.x, 10)
+		if test.uvarint != "" {
+			buf := x.AppendUvarint([]byte{0xaa})
+			if got := hex.EncodeToString(buf[1:]); got != test.uvarint || buf[0] != 0xaa {
+				t.Errorf("AppendUvarint(%s) = %s; want %s", test.x, got, test.uvarint)
+			}
+			if y, n := new(Int).SetUvarint(append(buf[1:], 0xbb)); y == nil || n != len(buf)-1 || y.Cmp(x) != 0 {
+				t.Errorf("SetUvarint(%s) = %v, %d; want %s", test.uvarint, y, n, test.x)
+			}
+			var y Int
+			if err := y.ReadUvarint(bytes.NewReader(buf[1:])); err != nil || y.Cmp(x) != 0 {
+				t.Errorf("ReadUvarint(%s) = %s, %v; want %s", test.uvarint, &y, err, test.x)
+			}
+		}
+		buf := x.AppendVarint(nil)
+		if got := hex.EncodeToString(buf); got != test.varint {
+			t.Errorf("AppendVarint(%s) = %s; want %s", test.x, got, test.varint)
+		}
+		if y, n := new(Int).SetVarint(buf); y == nil || n != len(buf) || y.Cmp(x) != 0 {
+			t.Errorf("SetVarint(%s) = %v, %d; want %s", test.varint, y, n, test.x)
+		}
+		var y Int
+		if err := y.ReadVarint(bytes.NewReader(buf)); err != nil || y.Cmp(x) != 0 {
+			t.Errorf("ReadVarint(%s) = %s, %v; want %s", test.varint, &y, err, test.x)
+		}
+	}
+
+	// Same as encoding/binary within 64 bits.
+	fu := func(u uint64) bool {
+		return bytes.Equal(new(Int).SetUint64(u).AppendUvarint(nil), binary.AppendUvarint(nil, u))
+	}
+	fs := func(i int64) bool {
+		return bytes.Equal(NewInt(i).AppendVarint(nil), binary.AppendVarint(nil, i))
+	}
+	if err := quick.Check(fu, nil); err != nil {
+		t.Error(err)
+	}
+	if err := quick.Check(fs, nil); err != nil {
+		t.Error(err)
+	}
+
+	// Round trips of large values, one after the other in a stream.
+	r := rand.New(rand.NewSource(1))
+	var buf []byte
+	var xs []*Int
+	for i := 0; i < 50; i++ {
+		b := make([]byte, r.Intn(250))
+		r.Read(b)
+		x := new(Int).SetBytes(b)
+		if i%2 == 1 {
+			x.Neg(x)
+		}
+		xs = append(xs, x)
+		buf = x.AppendVarint(buf)
+	}
+	br := bytes.NewReader(buf)
+	for _, x := range xs {
+		var y Int
+		if err := y.ReadVarint(br); err != nil || y.Cmp(x) != 0 {
+			t.Fatalf("ReadVarint = %s, %v; want %s", &y, err, x)
+		}
+	}
+	if err := new(Int).ReadVarint(br); err != io.EOF {
+		t.Errorf("ReadVarint at end returned %v; want io.EOF", err)
+	}
+	if err := new(Int).ReadUvarint(bytes.NewReader([]byte{0x80, 0x80})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadUvarint of truncated varint returned %v; want io.ErrUnexpectedEOF", err)
+	}
+	if y, n := new(Int).SetUvarint([]byte{0x80, 0x80}); y != nil || n != 0 {
+		t.Errorf("SetUvarint of truncated varint = %v, %d; want nil, 0", y, n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("AppendUvarint(-1) didn't panic")
+		}
+	}()
+	NewInt(-1).AppendUvarint(nil)
+}
@@ -0,0 +1,293 @@
+package gmp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// write count copies of text to s.
+func writeMultiple(s fmt.State, text string, count int) {
+	if len(text) > 0 {
+		b := []byte(text)
+		for ; count > 0; count-- {
+			s.Write(b)
+		}
+	}
+}
+
+var _ fmt.Formatter = (*Int)(nil) // *Int must implement fmt.Formatter
+
+// Format implements fmt.Formatter. It accepts the formats
+// 'b' (binary), 'o' (octal with 0 prefix), 'O' (octal with 0o prefix),
+// 'd' (decimal), 'x' (lowercase hexadecimal), and
+// 'X' (uppercase hexadecimal).
+// Also supported are the full suite of package fmt's format
+// flags for integral types, including '+' and ' ' for sign
+// control, '#' for leading zero in octal and for hexadecimal,
+// a leading "0x" or "0X" for "%#x" and "%#X" respectively,
+// specification of minimum digits precision, output field
+// width, space or zero padding, and '-' for left or right
+// justification.
+func (x *Int) Format(s fmt.State, ch rune) {
+	// determine base
+	var base int
+	switch ch {
+	case 'b':
+		base = 2
+	case 'o', 'O':
+		base = 8
+	case 'd', 's', 'v':
+		base = 10
+	case 'x', 'X':
+		base = 16
+	default:
+		// unknown format
+		str := "<nil>"
+		if x != nil {
+			str = x.String()
+		}
+		fmt.Fprintf(s, "%%!%c(gmp.Int=%s)", ch, str)
+		return
+	}
+
+	if x == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+
+	// determine sign character
+	sign := ""
+	switch {
+	case x.Sign() < 0:
+		sign = "-"
+	case s.Flag('+'): // supersedes ' ' when both specified
+		sign = "+"
+	case s.Flag(' '):
+		sign = " "
+	}
+
+	// determine prefix characters for indicating output base
+	prefix := ""
+	if s.Flag('#') {
+		switch ch {
+		case 'b': // binary
+			prefix = "0b"
+		case 'o': // octal
+			prefix = "0"
+		case 'x': // hexadecimal
+			prefix = "0x"
+		case 'X':
+			prefix = "0X"
+		}
+	}
+	if ch == 'O' {
+		prefix = "0o"
+	}
+
+	digits, _ := x.StringBase(base)
+	digits = strings.TrimPrefix(digits, "-")
+	if ch == 'X' {
+		digits = strings.ToUpper(digits)
+	}
+
+	// number of characters for the three classes of number padding
+	var left int  // space characters to left of digits for right justification ("%8d")
+	var zeros int // zero characters (actually cs[0]) as left-most digits ("%.8d")
+	var right int // space characters to right of digits for left justification ("%-8d")
+
+	// determine number padding from precision: the least number of digits to output
+	precision, precisionSet := s.Precision()
+	if precisionSet {
+		switch {
+		case len(digits) < precision:
+			zeros = precision - len(digits) // count of zero padding
+		case digits == "0" && precision == 0:
+			return // print nothing if zero value (x == 0) and zero precision ("." or ".0")
+		}
+	}
+
+	// determine field pad from width: the least number of characters to output
+	length := len(sign) + len(prefix) + zeros + len(digits)
+	if width, widthSet := s.Width(); widthSet && length < width { // pad as specified
+		switch d := width - length; {
+		case s.Flag('-'):
+			// pad on the right with spaces; supersedes '0' when both specified
+			right = d
+		case s.Flag('0') && !precisionSet:
+			// pad with zeros unless precision also specified
+			zeros = d
+		default:
+			// pad on the left with spaces
+			left = d
+		}
+	}
+
+	// print number as [left pad][sign][prefix][zero pad][digits][right pad]
+	writeMultiple(s, " ", left)
+	writeMultiple(s, sign, 1)
+	writeMultiple(s, prefix, 1)
+	writeMultiple(s, "0", zeros)
+	io.WriteString(s, digits)
+	writeMultiple(s, " ", right)
+}
+
+// MaxBase is the largest number base accepted for string conversions.
+//
+// Like in gmp, digits in bases up to 36 are 0-9 and a-z, with upper-case
+// letters accepted on input. In larger bases, digits are 0-9, A-Z for the
+// values 10 to 35, and a-z for 36 to 61.
+const MaxBase = 10 + 26 + 26
+
+// digitValue returns the value of the digit ch in base, or MaxBase if ch
+// isn't a digit.
+func digitValue(ch rune, base int) int {
+	switch {
+	case '0' <= ch && ch <= '9':
+		return int(ch - '0')
+	case 'A' <= ch && ch <= 'Z':
+		return int(ch - 'A' + 10)
+	case 'a' <= ch && ch <= 'z':
+		if base > 36 {
+			return int(ch - 'a' + 36)
+		}
+		return int(ch - 'a' + 10)
+	}
+	return MaxBase
+}
+
+// swapCase swaps upper and lower case ASCII letters in b. For bases above
+// 36 this converts between the digits of gmp, with the upper case letters
+// first, and those of math/big, with the lower case letters first.
+func swapCase(b []byte) {
+	for i, c := range b {
+		switch {
+		case 'a' <= c && c <= 'z':
+			b[i] = c - 'a' + 'A'
+		case 'A' <= c && c <= 'Z':
+			b[i] = c - 'A' + 'a'
+		}
+	}
+}
+
+// scanSign reads an optional '+' or '-' from s and reports whether it
+// was '-'.
+func scanSign(s fmt.ScanState) (neg bool, err error) {
+	ch, _, err := s.ReadRune()
+	if err != nil {
+		return false, err
+	}
+	switch ch {
+	case '-':
+		neg = true
+	case '+':
+		// nothing to do
+	default:
+		s.UnreadRune()
+	}
+	return
+}
+
+// scanDigits reads the longest run of digits in base from s and appends
+// them to buf. It returns an error if there are none.
+func scanDigits(s fmt.ScanState, base int, buf []byte) ([]byte, error) {
+	n := len(buf)
+	for {
+		ch, _, err := s.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return buf, err
+		}
+		if digitValue(ch, base) >= base {
+			s.UnreadRune()
+			break
+		}
+		buf = append(buf, byte(ch))
+	}
+	if len(buf) == n {
+		return buf, errors.New("gmp: number has no digits")
+	}
+	return buf, nil
+}
+
+// scanInt reads an optionally signed integer from s. If base is 0, the
+// base is taken from the prefix: "0b" selects base 2, "0", "0o" base 8,
+// "0x" base 16, and no prefix base 10. It returns the digits, prefixed with
+// '-' if negative, and the base they are in.
+func scanInt(s fmt.ScanState, base int) ([]byte, int, error) {
+	neg, err := scanSign(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	var buf []byte
+	if neg {
+		buf = append(buf, '-')
+	}
+	if base == 0 {
+		base = 10
+		if ch, _, err := s.ReadRune(); err == nil {
+			if ch != '0' {
+				s.UnreadRune()
+			} else {
+				buf = append(buf, '0')
+				ch, _, err := s.ReadRune()
+				if err != nil {
+					// a lone "0"
+					return buf, base, nil
+				}
+				switch ch {
+				case 'b', 'B':
+					base = 2
+				case 'o', 'O':
+					base = 8
+				case 'x', 'X':
+					base = 16
+				default:
+					s.UnreadRune()
+					if digitValue(ch, 8) >= 8 {
+						return buf, base, nil
+					}
+					base = 8
+				}
+				buf = buf[:len(buf)-1]
+			}
+		}
+	}
+	buf, err = scanDigits(s, base, buf)
+	return buf, base, err
+}
+
+// Scan is a support routine for fmt.Scanner; it sets z to the value of
+// the scanned number. It accepts the formats 'b' (binary), 'o' (octal),
+// 'd' (decimal), 'x' (lowercase hexadecimal), and 'X' (uppercase
+// hexadecimal). With 'v' and 's' the base is taken from an optional
+// "0b", "0", "0o" or "0x" prefix.
+func (z *Int) Scan(s fmt.ScanState, ch rune) error {
+	s.SkipSpace() // skip leading space characters
+	base := 0
+	switch ch {
+	case 'b':
+		base = 2
+	case 'o':
+		base = 8
+	case 'd':
+		base = 10
+	case 'x', 'X':
+		base = 16
+	case 's', 'v':
+		// let scanInt determine the base
+	default:
+		return errors.New("gmp: Int.Scan: invalid verb")
+	}
+	buf, base, err := scanInt(s, base)
+	if err != nil {
+		return err
+	}
+	if _, ok := z.SetString(string(buf), base); !ok {
+		return errors.New("gmp: Int.Scan: invalid number")
+	}
+	return nil
+}
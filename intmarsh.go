@@ -0,0 +1,116 @@
+package gmp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Gob codec version. Permits backward-compatible changes to the encoding.
+const intGobVersion byte = 1
+
+// The binary encodings store magnitudes as little-endian 64-bit limbs,
+// least significant first, whatever the host's byte order.
+const limbBytes = 8
+
+// appendLimbs appends the magnitude of x to buf in limbs.
+func appendLimbs(buf []byte, x *Int) []byte {
+	return append(buf, x.Export(-1, limbBytes, -1, 0)...)
+}
+
+// setLimbs sets z to the magnitude in limbs stored in buf.
+func setLimbs(z *Int, buf []byte) error {
+	if len(buf)%limbBytes != 0 {
+		return errors.New("truncated limb")
+	}
+	z.Import(buf, -1, limbBytes, -1, 0)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x *Int) MarshalText() (text []byte, err error) {
+	if x == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The
+// base is taken from an optional "0b", "0" or "0x" prefix, like SetString
+// with base 0.
+func (z *Int) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text), 0); !ok {
+		return fmt.Errorf("gmp: cannot unmarshal %q into a *gmp.Int", text)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. x is written as a
+// JSON number, or as a string if SetJSONQuote(true) was called. A nil x is
+// written as null.
+func (x *Int) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	return marshalJSON([]byte(x.String()), GetJSONQuote()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// decimal integers written as JSON numbers or strings. null leaves z
+// unchanged, so a *Int decoded from null stays nil.
+func (z *Int) UnmarshalJSON(data []byte) error {
+	text, null, err := unmarshalJSON(data)
+	if err != nil || null {
+		return err
+	}
+	if _, ok := z.SetString(string(text), 10); !ok {
+		return fmt.Errorf("gmp: cannot unmarshal %s into a *gmp.Int", data)
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding is a version and sign byte followed by the magnitude of x in
+// 64-bit limbs.
+func (x *Int) MarshalBinary() ([]byte, error) {
+	b := intGobVersion << 1 // make space for sign bit
+	if x.Sign() < 0 {
+		b |= 1
+	}
+	return appendLimbs([]byte{b}, x), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (z *Int) UnmarshalBinary(buf []byte) error {
+	if len(buf) == 0 {
+		return errors.New("gmp: Int.UnmarshalBinary: no data")
+	}
+	b := buf[0]
+	if b>>1 != intGobVersion {
+		return fmt.Errorf("gmp: Int.UnmarshalBinary: encoding version %d not supported", b>>1)
+	}
+	if err := setLimbs(z, buf[1:]); err != nil {
+		return fmt.Errorf("gmp: Int.UnmarshalBinary: %v", err)
+	}
+	if b&1 != 0 {
+		z.Neg(z)
+	}
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (x *Int) GobEncode() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return x.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *Int) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		// Other side sent a nil or default value.
+		z.SetInt64(0)
+		return nil
+	}
+	return z.UnmarshalBinary(buf)
+}
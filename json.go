@@ -0,0 +1,52 @@
+package gmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+)
+
+var jsonQuote atomic.Bool
+
+// SetJSONQuote sets whether MarshalJSON writes Int and Float values as
+// quoted JSON strings rather than JSON numbers. Many JSON decoders,
+// JavaScript's among them, read numbers as float64 and silently lose
+// precision; they get the exact value from a string. Rat values are always
+// written as strings. SetJSONQuote returns the previous setting.
+func SetJSONQuote(quote bool) bool {
+	return jsonQuote.Swap(quote)
+}
+
+// GetJSONQuote returns the setting made by SetJSONQuote.
+func GetJSONQuote() bool {
+	return jsonQuote.Load()
+}
+
+// marshalJSON returns the JSON encoding of a value with the given text
+// representation: a number, or a string if quote is set.
+func marshalJSON(text []byte, quote bool) []byte {
+	if !quote {
+		return text
+	}
+	b := make([]byte, 0, len(text)+2)
+	b = append(b, '"')
+	b = append(b, text...)
+	return append(b, '"')
+}
+
+// unmarshalJSON returns the text of a JSON number or string, and whether
+// it is the JSON null.
+func unmarshalJSON(data []byte) (text []byte, null bool, err error) {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil, true, nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, false, err
+		}
+		return []byte(s), false, nil
+	}
+	return data, false, nil
+}
@@ -0,0 +1,13 @@
+package gmp
+
+// A LiveObject describes an Int, Rat or Float whose gmp storage has been
+// allocated but not yet freed, either by Clear or by its cleanup.
+type LiveObject struct {
+	Type  string // "Int", "Rat" or "Float"
+	Stack string // stack trace of the call that initialized the value
+}
+
+// String returns the type and stack trace of o.
+func (o LiveObject) String() string {
+	return "gmp." + o.Type + " allocated at:\n" + o.Stack
+}
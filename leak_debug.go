@@ -0,0 +1,106 @@
+//go:build gmpdebug
+
+package gmp
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// liveObject is the record kept by trackInit for every initialized value.
+type liveObject struct {
+	typ string
+	seq uint64
+	pcs []uintptr
+}
+
+var live struct {
+	sync.Mutex
+	seq     uint64
+	objects map[uintptr]liveObject
+}
+
+// trackInit records the allocation site of the value at p. The address
+// is stored as a uintptr so the record doesn't keep the value alive.
+func trackInit(p unsafe.Pointer, typ string) {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(3, pcs)] // skip Callers, trackInit and doinit
+
+	live.Lock()
+	defer live.Unlock()
+	if live.objects == nil {
+		live.objects = make(map[uintptr]liveObject)
+	}
+	live.seq++
+	live.objects[uintptr(p)] = liveObject{typ, live.seq, pcs}
+}
+
+// trackClear forgets the value at p once its storage has been freed.
+func trackClear(p unsafe.Pointer) {
+	live.Lock()
+	defer live.Unlock()
+	delete(live.objects, uintptr(p))
+}
+
+// trackCollect forgets the value at p, just recorded by trackInit, once the
+// garbage collector frees it. It stands in for the trackClear of the cgo
+// cleanup when the value's storage is Go memory. The sequence number keeps
+// the cleanup from forgetting a later value at the same address.
+func trackCollect(p unsafe.Pointer) {
+	live.Lock()
+	seq := live.objects[uintptr(p)].seq
+	live.Unlock()
+	runtime.AddCleanup((*byte)(p), untrack, liveKey{uintptr(p), seq})
+}
+
+type liveKey struct {
+	addr uintptr
+	seq  uint64
+}
+
+func untrack(k liveKey) {
+	live.Lock()
+	defer live.Unlock()
+	if o, ok := live.objects[k.addr]; ok && o.seq == k.seq {
+		delete(live.objects, k.addr)
+	}
+}
+
+// LiveObjects returns the values whose gmp storage has not been freed yet,
+// in the order they were initialized. It is only available when the
+// package is built with the gmpdebug tag; otherwise it returns nil.
+//
+// Values that are unreachable but not yet cleaned up are reported too, so
+// callers looking for leaks should run runtime.GC first.
+func LiveObjects() []LiveObject {
+	live.Lock()
+	objs := make([]liveObject, 0, len(live.objects))
+	for _, o := range live.objects {
+		objs = append(objs, o)
+	}
+	live.Unlock()
+
+	sort.Slice(objs, func(i, j int) bool { return objs[i].seq < objs[j].seq })
+	res := make([]LiveObject, len(objs))
+	for i, o := range objs {
+		res[i] = LiveObject{Type: o.typ, Stack: formatStack(o.pcs)}
+	}
+	return res
+}
+
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
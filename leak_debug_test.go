@@ -0,0 +1,67 @@
+//go:build gmpdebug
+
+package gmp
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func isLive(typ, fn string) bool {
+	for _, o := range LiveObjects() {
+		if o.Type == typ && strings.Contains(o.Stack, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+func allocForLeakTest() (*Int, *Rat, *Float) {
+	return NewInt(1), NewRat(1, 2), NewFloat(0.5)
+}
+
+func TestLiveObjects(t *testing.T) {
+	z, q, f := allocForLeakTest()
+	for _, typ := range []string{"Int", "Rat", "Float"} {
+		if !isLive(typ, "allocForLeakTest") {
+			t.Errorf("%s allocated by allocForLeakTest not reported live", typ)
+		}
+	}
+
+	z.Clear()
+	q.Clear()
+	f.Clear()
+	for _, typ := range []string{"Int", "Rat", "Float"} {
+		if isLive(typ, "allocForLeakTest") {
+			t.Errorf("%s reported live after Clear", typ)
+		}
+	}
+}
+
+func allocForCollectTest() {
+	NewInt(1)
+	NewRat(1, 2)
+	NewFloat(0.5)
+}
+
+func TestLiveObjectsCollected(t *testing.T) {
+	allocForCollectTest()
+	// Cleanups run asynchronously after the collection that finds the
+	// values unreachable.
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		if !isLive("Int", "allocForCollectTest") &&
+			!isLive("Rat", "allocForCollectTest") &&
+			!isLive("Float", "allocForCollectTest") {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, typ := range []string{"Int", "Rat", "Float"} {
+		if isLive(typ, "allocForCollectTest") {
+			t.Errorf("%s reported live after it was collected", typ)
+		}
+	}
+}
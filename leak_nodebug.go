@@ -0,0 +1,20 @@
+//go:build !gmpdebug
+
+package gmp
+
+import (
+	"unsafe"
+)
+
+func trackInit(p unsafe.Pointer, typ string) {}
+
+func trackClear(p unsafe.Pointer) {}
+
+func trackCollect(p unsafe.Pointer) {}
+
+// LiveObjects returns the values whose gmp storage has not been freed yet,
+// in the order they were initialized. It is only available when the
+// package is built with the gmpdebug tag; otherwise it returns nil.
+func LiveObjects() []LiveObject {
+	return nil
+}
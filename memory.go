@@ -0,0 +1,67 @@
+package gmp
+
+import (
+	"math"
+	"runtime"
+	"sync/atomic"
+)
+
+// MemoryStats records statistics about the memory allocated by gmp on
+// behalf of Int, Rat and Float values.
+type MemoryStats struct {
+	Live   uint64 // bytes currently allocated
+	Peak   uint64 // maximum value of Live so far
+	Allocs uint64 // cumulative count of blocks allocated
+	Frees  uint64 // cumulative count of blocks freed
+}
+
+// MemStats returns the current gmp memory statistics.
+//
+// gmp allocates its limbs with malloc, outside of the Go heap, so this
+// memory does not show up in runtime.MemStats. When the package is built
+// without cgo, values live on the Go heap and all statistics are zero.
+func MemStats() MemoryStats {
+	return readMemStats()
+}
+
+var (
+	gcThreshold atomic.Uint64
+	gcNext      atomic.Uint64
+)
+
+// SetGCThreshold makes the package force a garbage collection when a new
+// value is initialized after the memory allocated by gmp has grown by more
+// than threshold bytes since the last forced collection. Collections let
+// cleanups release values that were never cleared, which the Go runtime
+// would otherwise not schedule since it cannot see gmp's memory.
+// A threshold of 0, the default, disables forced collections.
+// SetGCThreshold returns the previous threshold.
+func SetGCThreshold(threshold uint64) uint64 {
+	gcNext.Store(liveBytes() + threshold)
+	return gcThreshold.Swap(threshold)
+}
+
+// GetGCThreshold returns the threshold set by SetGCThreshold.
+func GetGCThreshold() uint64 {
+	return gcThreshold.Load()
+}
+
+// gcPressure forces a garbage collection if gmp's memory has grown past
+// the threshold set by SetGCThreshold.
+func gcPressure() {
+	t := gcThreshold.Load()
+	if t == 0 {
+		return
+	}
+	next := gcNext.Load()
+	if liveBytes() < next {
+		return
+	}
+	// Claim the collection, so that goroutines crossing the threshold at
+	// the same time don't each run one.
+	if !gcNext.CompareAndSwap(next, math.MaxUint64) {
+		return
+	}
+	runtime.GC()
+	gcNext.Store(liveBytes() + t)
+}
@@ -0,0 +1,107 @@
+//go:build cgo
+
+package gmp
+
+/*
+#cgo LDFLAGS: -lgmp
+#include <gmp.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+// Counters for every block handed out by the allocation functions below.
+// They are updated atomically since gmp may be called from any thread.
+static unsigned long long _gmp_live, _gmp_peak, _gmp_allocs, _gmp_frees;
+
+static void _gmp_account(size_t add, size_t sub) {
+	unsigned long long live, peak;
+
+	live = __atomic_add_fetch(&_gmp_live, add, __ATOMIC_RELAXED);
+	live = __atomic_sub_fetch(&_gmp_live, sub, __ATOMIC_RELAXED);
+	peak = __atomic_load_n(&_gmp_peak, __ATOMIC_RELAXED);
+	while (live > peak &&
+	       !__atomic_compare_exchange_n(&_gmp_peak, &peak, live, 1,
+	                                    __ATOMIC_RELAXED, __ATOMIC_RELAXED)) {
+	}
+}
+
+// gmp requires the allocation functions to never return NULL.
+static void _gmp_oom(size_t n) {
+	fprintf(stderr, "gmp: cannot allocate %zu bytes\n", n);
+	abort();
+}
+
+static void *_gmp_alloc(size_t n) {
+	void *p = malloc(n);
+	if (p == NULL) {
+		_gmp_oom(n);
+	}
+	__atomic_add_fetch(&_gmp_allocs, 1, __ATOMIC_RELAXED);
+	_gmp_account(n, 0);
+	return p;
+}
+
+static void *_gmp_realloc(void *p, size_t old, size_t n) {
+	p = realloc(p, n);
+	if (p == NULL) {
+		_gmp_oom(n);
+	}
+	_gmp_account(n, old);
+	return p;
+}
+
+static void _gmp_free(void *p, size_t n) {
+	free(p);
+	__atomic_add_fetch(&_gmp_frees, 1, __ATOMIC_RELAXED);
+	_gmp_account(0, n);
+}
+
+// Install the allocation functions before anything (including package
+// level variables such as intZero) allocates through gmp.
+__attribute__((constructor)) static void _gmp_install(void) {
+	mp_set_memory_functions(_gmp_alloc, _gmp_realloc, _gmp_free);
+}
+
+static void _gmp_memstats(unsigned long long *s) {
+	s[0] = __atomic_load_n(&_gmp_live, __ATOMIC_RELAXED);
+	s[1] = __atomic_load_n(&_gmp_peak, __ATOMIC_RELAXED);
+	s[2] = __atomic_load_n(&_gmp_allocs, __ATOMIC_RELAXED);
+	s[3] = __atomic_load_n(&_gmp_frees, __ATOMIC_RELAXED);
+}
+
+static unsigned long long _gmp_live_bytes(void) {
+	return __atomic_load_n(&_gmp_live, __ATOMIC_RELAXED);
+}
+
+// Strings returned by mpz_get_str and friends are allocated by gmp and
+// are exactly strlen(s)+1 bytes long.
+static void _gmp_free_str(char *s) {
+	void (*freefunc)(void *, size_t);
+
+	mp_get_memory_functions(NULL, NULL, &freefunc);
+	freefunc(s, strlen(s) + 1);
+}
+*/
+import "C"
+
+// readMemStats returns the counters kept by the allocation functions.
+func readMemStats() MemoryStats {
+	var s [4]C.ulonglong
+	C._gmp_memstats(&s[0])
+	return MemoryStats{
+		Live:   uint64(s[0]),
+		Peak:   uint64(s[1]),
+		Allocs: uint64(s[2]),
+		Frees:  uint64(s[3]),
+	}
+}
+
+// liveBytes returns the number of bytes currently allocated by gmp.
+func liveBytes() uint64 {
+	return uint64(C._gmp_live_bytes())
+}
+
+// freeString frees a string allocated by one of gmp's *_get_str functions.
+func freeString(p *C.char) {
+	C._gmp_free_str(p)
+}
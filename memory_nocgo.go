@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package gmp
+
+// Without cgo, Int, Rat and Float are backed by math/big and their memory
+// is managed by the Go runtime, so there is nothing to account for.
+
+func readMemStats() MemoryStats {
+	return MemoryStats{}
+}
+
+func liveBytes() uint64 {
+	return 0
+}
@@ -0,0 +1,140 @@
+//go:build cgo
+
+package gmp
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemStats(t *testing.T) {
+	before := MemStats()
+
+	z := new(Int).Lsh(NewInt(1), 1<<16)
+	grown := MemStats()
+	if grown.Live < before.Live+(1<<16)/8 {
+		t.Errorf("Live = %d after allocating 2^16 bits; want at least %d", grown.Live, before.Live+(1<<16)/8)
+	}
+	if grown.Peak < grown.Live {
+		t.Errorf("Peak = %d; want >= Live = %d", grown.Peak, grown.Live)
+	}
+	if grown.Allocs <= before.Allocs {
+		t.Errorf("Allocs = %d; want > %d", grown.Allocs, before.Allocs)
+	}
+
+	_ = z.String() // strings returned by gmp must be accounted for too
+	z.Clear()
+	after := MemStats()
+	if after.Live >= grown.Live {
+		t.Errorf("Live = %d after Clear; want < %d", after.Live, grown.Live)
+	}
+	if after.Frees <= grown.Frees {
+		t.Errorf("Frees = %d; want > %d", after.Frees, grown.Frees)
+	}
+}
+
+// waitFrees runs the garbage collector until gmp's Frees count reaches
+// want, giving the cleanups time to run, and returns the statistics.
+func waitFrees(want uint64) MemoryStats {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runtime.GC()
+		m := MemStats()
+		if m.Frees >= want || time.Now().After(deadline) {
+			return m
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestIntCleanup(t *testing.T) {
+	// Values that are never cleared must be released by their cleanup
+	// without disturbing live values.
+	const n = 1000
+	keep := NewInt(1)
+	before := MemStats()
+	for i := 0; i < n; i++ {
+		new(Int).Lsh(keep, 4096)
+	}
+	// Every value allocated its mpz and its limbs.
+	after := waitFrees(before.Frees + 2*n)
+	if after.Frees < before.Frees+2*n {
+		t.Errorf("Frees = %d; want at least %d", after.Frees, before.Frees+2*n)
+	}
+	if after.Live > before.Live+n*4096/8/2 {
+		t.Errorf("Live = %d; want about %d", after.Live, before.Live)
+	}
+	if s := keep.String(); s != "1" {
+		t.Errorf("got %s; want 1", s)
+	}
+}
+
+func TestCleanupInStruct(t *testing.T) {
+	// Values inside structs and slices are released with them.
+	const n = 100
+	before := MemStats()
+	for i := 0; i < n; i++ {
+		p := new(struct {
+			x Int
+			q Rat
+			f Float
+		})
+		p.x.Lsh(intOne, 4096)
+		p.q.SetFrac64(1, 3)
+		p.f.SetInt64(1)
+		s := make([]Int, 3)
+		s[1].Lsh(intOne, 4096)
+	}
+	after := waitFrees(before.Frees + 4*n)
+	if after.Frees < before.Frees+4*n {
+		t.Errorf("Frees = %d; want at least %d", after.Frees, before.Frees+4*n)
+	}
+	if after.Live > before.Live+n*4096/8 {
+		t.Errorf("Live = %d; want about %d", after.Live, before.Live)
+	}
+}
+
+func TestGCThreshold(t *testing.T) {
+	old := SetGCThreshold(1 << 20)
+	defer SetGCThreshold(old)
+	if got := GetGCThreshold(); got != 1<<20 {
+		t.Fatalf("GetGCThreshold() = %d; want %d", got, 1<<20)
+	}
+
+	// Without forced collections the garbage below would only be freed
+	// whenever the Go heap happens to trigger a cycle.
+	x := NewInt(1)
+	for i := 0; i < 200; i++ {
+		new(Int).Lsh(x, 1<<20)
+	}
+	if live := MemStats().Live; live > 8<<20 {
+		t.Errorf("Live = %d; want it bounded by forced collections", live)
+	}
+}
+
+func TestGCPressureOnce(t *testing.T) {
+	old := SetGCThreshold(1 << 30)
+	defer SetGCThreshold(old)
+	gcNext.Store(0) // a collection is due
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			gcPressure()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	runtime.ReadMemStats(&after)
+	if n := after.NumForcedGC - before.NumForcedGC; n != 1 {
+		t.Errorf("%d concurrent collections; want 1", n)
+	}
+}
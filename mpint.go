@@ -0,0 +1,88 @@
+package gmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteMPInt writes x to w as an SSH mpint (RFC 4251, section 5): the
+// length of the minimal two's complement representation of x as a
+// big-endian uint32, followed by the representation. Zero has length 0.
+// WriteMPInt returns the number of bytes written.
+func (x *Int) WriteMPInt(w io.Writer) (int, error) {
+	var b []byte
+	if x.Sign() != 0 {
+		b = x.SignedBytes()
+	}
+	if uint64(len(b)) > math.MaxUint32 {
+		return 0, errors.New("gmp: Int.WriteMPInt: value too large")
+	}
+	buf := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(buf, uint32(len(b)))
+	return w.Write(append(buf, b...))
+}
+
+// ReadMPInt sets z to an SSH mpint read from r, and returns the number of
+// bytes read. Like RFC 4251 requires, the representation must not have
+// unnecessary leading 0x00 or 0xff bytes.
+func (z *Int) ReadMPInt(r io.Reader) (int, error) {
+	var hdr [4]byte
+	if n, err := io.ReadFull(r, hdr[:]); err != nil {
+		return n, err
+	}
+	b, err := readFull(r, int64(binary.BigEndian.Uint32(hdr[:])))
+	if err != nil {
+		return 4 + len(b), err
+	}
+	if len(b) == 0 {
+		z.SetInt64(0)
+		return 4, nil
+	}
+	if _, err := setMinimalSignedBytes(z, b); err != nil {
+		return 4 + len(b), fmt.Errorf("gmp: Int.ReadMPInt: %v", err)
+	}
+	return 4 + len(b), nil
+}
+
+// WriteMPI writes x to w as an OpenPGP multiprecision integer (RFC 4880,
+// section 3.2): the bit length of x as a big-endian uint16, followed by the
+// big-endian magnitude of x. x must not be negative and must have at most
+// 65535 bits. WriteMPI returns the number of bytes written.
+func (x *Int) WriteMPI(w io.Writer) (int, error) {
+	if x.Sign() < 0 {
+		return 0, errors.New("gmp: Int.WriteMPI: negative value")
+	}
+	bits := x.BitLen()
+	if bits > math.MaxUint16 {
+		return 0, errors.New("gmp: Int.WriteMPI: value too large")
+	}
+	buf := make([]byte, 2, 2+(bits+7)/8)
+	binary.BigEndian.PutUint16(buf, uint16(bits))
+	return w.Write(append(buf, x.Bytes()...))
+}
+
+// ReadMPI sets z to an OpenPGP multiprecision integer read from r, and
+// returns the number of bytes read. The bit length must be exact.
+func (z *Int) ReadMPI(r io.Reader) (int, error) {
+	var hdr [2]byte
+	if n, err := io.ReadFull(r, hdr[:]); err != nil {
+		return n, err
+	}
+	bits := int(binary.BigEndian.Uint16(hdr[:]))
+	b := make([]byte, (bits+7)/8)
+	n, err := io.ReadFull(r, b)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 2 + n, err
+	}
+	z.SetBytes(b)
+	if z.BitLen() != bits {
+		return 2 + n, fmt.Errorf("gmp: Int.ReadMPI: bit length %d given for a %d-bit value", bits, z.BitLen())
+	}
+	return 2 + n, nil
+}
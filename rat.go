@@ -1,3 +1,5 @@
+//go:build cgo
+
 // Copyright 2010 Utkan Güngördü.
 // Based on $(GOROOT)/misc/cgo/gmp/gmp.go
 // Released under the BSD-style license that can
@@ -28,17 +30,41 @@ mpz_ptr _mpq_denref(mpq_t q) {
 int _mpq_sgn(mpq_t q) {
 	return mpq_sgn(q);
 }
+
+// The mpq struct is allocated with gmp's allocation function, see _mpz_new.
+mpq_ptr _mpq_new(void) {
+	void *(*alloc)(size_t);
+	mp_get_memory_functions(&alloc, NULL, NULL);
+	mpq_ptr q = alloc(sizeof(__mpq_struct));
+	mpq_init(q);
+	return q;
+}
+void _mpq_delete(mpq_ptr q) {
+	void (*dealloc)(void *, size_t);
+	mp_get_memory_functions(NULL, NULL, &dealloc);
+	mpq_clear(q);
+	dealloc(q, sizeof(__mpq_struct));
+}
 */
 import "C"
 
 import (
 	"os"
+	"runtime"
 	"unsafe"
 )
 
+// A Rat represents a quotient a/b of arbitrary precision.
+// The zero value for a Rat represents the value 0.
+//
+// A Rat must not be copied by value once it has been used; doing so
+// panics. Pass *Rat instead.
 type Rat struct {
-	i    C.mpq_t
-	init bool
+	noCopy  noCopy
+	addr    *Rat     // of receiver, to detect copies by value
+	i       *C.mpq_t // in C memory, see Int.doinit
+	cleanup runtime.Cleanup
+	init    bool
 }
 
 // NewRat creates a new Rat with numerator a and denominator b.
@@ -47,21 +73,44 @@ func NewRat(x int64, y int64) *Rat { return new(Rat).SetFrac64(x, y) }
 // Int promises that the zero value is a 0, but in gmp
 // the zero value is a crash.  To bridge the gap, the
 // init bool says whether this is a valid gmp value.
-// doinit initializes z.i if it needs it.  This is not inherent
-// to FFI, just a mismatch between Go's convention of
+// doinit allocates and initializes q.i if it needs it.  This is not
+// inherent to FFI, just a mismatch between Go's convention of
 // making zero values useful and gmp's decision not to.
+//
+// doinit also registers a cleanup so the numerator and denominator are
+// released once q becomes unreachable, even if Clear is never called.
 func (q *Rat) doinit() {
 	if q.init {
+		q.copyCheck()
 		return
 	}
 	q.init = true
-	C.mpq_init(&q.i[0])
+	q.addr = q
+	q.i = (*C.mpq_t)(unsafe.Pointer(C._mpq_new()))
+	trackInit(unsafe.Pointer(q.i), "Rat")
+	q.cleanup = runtime.AddCleanup(q, clearRat, q.i)
+	gcPressure()
+}
+
+// clearRat frees the mpq p allocated by doinit.
+func clearRat(p *C.mpq_t) {
+	trackClear(unsafe.Pointer(p))
+	C._mpq_delete(&p[0])
+}
+
+// copyCheck panics if q is an initialized Rat that was copied by value.
+func (q *Rat) copyCheck() {
+	if q.addr != q {
+		panic("gmp: illegal use of non-zero Rat copied by value")
+	}
 }
 
 // Set sets z = x and returns z.
 func (q *Rat) Set(x *Rat) *Rat {
+	x.doinit()
 	q.doinit()
 	C.mpq_set(&q.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return q
 }
 
@@ -114,39 +163,38 @@ func (q *Rat) SetInt(x *Int) *Rat {
 	q.doinit()
 	x.doinit()
 	C.mpq_set_z(&q.i[0], x.ptr)
+	runtime.KeepAlive(x)
 	return q
 }
 
 // SetStringBase interprets s as a number in the given base
-// and sets z to that value.  The base must be in the range [2,36].
+// and sets z to that value.  The base must be in the range [2,MaxBase].
 // SetString returns an error if s cannot be parsed or the base is invalid.
 func (q *Rat) SetStringBase(s string, base int) (*Rat, bool) {
 	q.doinit()
-	if base < 2 || base > 36 {
+	if base < 2 || base > MaxBase {
 		return nil, false
 	}
 	p := C.CString(s)
 	defer C.free(unsafe.Pointer(p))
 	if C.mpq_set_str(&q.i[0], p, C.int(base)) < 0 {
+		runtime.KeepAlive(q)
+		return nil, false
+	}
+	if C.mpz_size(C._mpq_denref(&q.i[0])) == 0 { // zero denominator
+		runtime.KeepAlive(q)
 		return nil, false
 	}
 	C.mpq_canonicalize(&q.i[0])
 	return q, true
 }
 
-// SetString sets z to the value of s and returns z and a boolean indicating
-// success. s can be given as a fraction "a/b" or as a floating-point number
-// optionally followed by an exponent. If the operation failed, the value of
-// z is undefined but the returned value is nil.
-func (q *Rat) SetString(s string) (*Rat, bool) {
-	q.doinit()
-	return q.SetStringBase(s, 10)
-}
-
 func SwapRat(x, y *Rat) {
 	x.doinit()
 	y.doinit()
 	C.mpq_swap(&x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 }
 
 // String returns the representation of z in the given base.
@@ -154,13 +202,14 @@ func (q *Rat) StringBase(base int) (string, error) {
 	if q == nil {
 		return "nil", nil
 	}
-	if base < 2 || base > 36 {
+	if base < 2 || base > MaxBase {
 		return "", os.ErrInvalid
 	}
 	q.doinit()
 	p := C.mpq_get_str(nil, C.int(base), &q.i[0])
 	s := C.GoString(p)
-	C.free(unsafe.Pointer(p))
+	freeString(p)
+	runtime.KeepAlive(q)
 	return s, nil
 }
 
@@ -177,7 +226,7 @@ func (q *Rat) RatString() string {
 func (q *Rat) String() string {
 	q.doinit()
 	s := q.RatString()
-	if len(s) < 3 { // s not in the form a/b
+	if q.IsInt() { // s not in the form a/b
 		s = s + "/1"
 	}
 	return s
@@ -185,7 +234,9 @@ func (q *Rat) String() string {
 
 func (q *Rat) Float64() float64 {
 	q.doinit()
-	return float64(C.mpq_get_d(&q.i[0]))
+	r := float64(C.mpq_get_d(&q.i[0]))
+	runtime.KeepAlive(q)
+	return r
 }
 
 // SetFloat64 sets f = x and returns q.
@@ -197,18 +248,28 @@ func (q *Rat) SetFloat64(x float64) *Rat {
 
 // SetFloat sets f = x and returns f.
 func (q *Rat) SetFloat(x *Float) *Rat {
+	x.doinit()
 	q.doinit()
 	C.mpq_set_f(&q.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return q
 }
 
 func (q *Rat) destroy() {
 	if q.init {
-		C.mpq_clear(&q.i[0])
+		q.copyCheck()
+		q.cleanup.Stop()
+		clearRat(q.i)
 	}
+	q.i = nil
 	q.init = false
 }
 
+// Clear frees the space occupied by the underlying gmp object. Calling
+// Clear is optional: the space is also freed by a cleanup once q becomes
+// unreachable, but Clear releases it immediately. It is safe to call Clear
+// more than once, and q may be reused afterwards; it then holds the value 0.
+// References returned by Num and Denom must not be used after Clear.
 func (q *Rat) Clear() {
 	q.destroy()
 }
@@ -219,6 +280,8 @@ func (q *Rat) Add(x, y *Rat) *Rat {
 	y.doinit()
 	q.doinit()
 	C.mpq_add(&q.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return q
 }
 
@@ -227,6 +290,8 @@ func (q *Rat) Sub(x, y *Rat) *Rat {
 	y.doinit()
 	q.doinit()
 	C.mpq_sub(&q.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return q
 }
 
@@ -235,6 +300,8 @@ func (q *Rat) Mul(x, y *Rat) *Rat {
 	y.doinit()
 	q.doinit()
 	C.mpq_mul(&q.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return q
 }
 
@@ -243,6 +310,7 @@ func (z *Rat) Neg(x *Rat) *Rat {
 	z.doinit()
 	x.doinit()
 	C.mpq_neg(&z.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return z
 }
 
@@ -251,6 +319,8 @@ func (q *Rat) Quo(x, y *Rat) *Rat {
 	y.doinit()
 	q.doinit()
 	C.mpq_div(&q.i[0], &x.i[0], &y.i[0])
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
 	return q
 }
 
@@ -259,6 +329,7 @@ func (q *Rat) Abs(x *Rat) *Rat {
 	x.doinit()
 	q.doinit()
 	C.mpq_abs(&q.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return q
 }
 
@@ -266,6 +337,7 @@ func (q *Rat) Inv(x *Rat) *Rat {
 	x.doinit()
 	q.doinit()
 	C.mpq_inv(&q.i[0], &x.i[0])
+	runtime.KeepAlive(x)
 	return q
 }
 
@@ -274,6 +346,7 @@ func (q *Rat) Mul2Exp(x *Rat, s uint) *Rat {
 	x.doinit()
 	q.doinit()
 	C.mpq_mul_2exp(&q.i[0], &x.i[0], C.mp_bitcnt_t(s))
+	runtime.KeepAlive(x)
 	return q
 }
 
@@ -282,6 +355,7 @@ func (q *Rat) Div2Exp(x *Rat, s uint) *Rat {
 	x.doinit()
 	q.doinit()
 	C.mpq_div_2exp(&q.i[0], &x.i[0], C.mp_bitcnt_t(s))
+	runtime.KeepAlive(x)
 	return q
 }
 
@@ -295,7 +369,10 @@ func (x *Rat) Cmp(y *Rat) int {
 	x.doinit()
 	y.doinit()
 
-	switch cmp := int(C.mpq_cmp(&x.i[0], &y.i[0])); {
+	cmp := int(C.mpq_cmp(&x.i[0], &y.i[0]))
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	switch {
 	case cmp < 0:
 		return -1
 	case cmp == 0:
@@ -334,13 +411,18 @@ func (q *Rat) IsInt() bool {
 //
 func (x *Rat) Sign() int {
 	x.doinit()
-	return int(C._mpq_sgn(&x.i[0]))
+	r := int(C._mpq_sgn(&x.i[0]))
+	runtime.KeepAlive(x)
+	return r
 }
 
 func EqRat(x, y *Rat) bool {
 	x.doinit()
 	y.doinit()
-	return C.mpq_equal(&x.i[0], &y.i[0]) != 0
+	r := C.mpq_equal(&x.i[0], &y.i[0]) != 0
+	runtime.KeepAlive(x)
+	runtime.KeepAlive(y)
+	return r
 }
 
 // Num returns the numerator of x; it may be <= 0. The result is a reference
@@ -350,7 +432,9 @@ func (q *Rat) Num() *Int {
 	q.doinit()
 	n := new(Int)
 	n.init = true
+	n.addr = n
 	n.ptr = C._mpq_numref(&q.i[0])
+	n.ref = q
 	return n
 }
 
@@ -364,6 +448,8 @@ func (q *Rat) Denom() *Int {
 	q.doinit()
 	n := new(Int)
 	n.init = true
+	n.addr = n
 	n.ptr = C._mpq_denref(&q.i[0])
+	n.ref = q
 	return n
 }
@@ -0,0 +1,332 @@
+//go:build !cgo
+
+package gmp
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"unsafe"
+)
+
+// A Rat represents a quotient a/b of arbitrary precision.
+// The zero value for a Rat represents the value 0.
+//
+// A Rat must not be copied by value once it has been used; doing so
+// panics. Pass *Rat instead.
+type Rat struct {
+	noCopy noCopy
+	addr   *Rat // of receiver, to detect copies by value
+	i      big.Rat
+	init   bool
+}
+
+// NewRat creates a new Rat with numerator a and denominator b.
+func NewRat(x int64, y int64) *Rat { return new(Rat).SetFrac64(x, y) }
+
+// doinit marks q as initialized. It sets q.i explicitly to 0 so that the
+// denominator is materialized and Denom can return a reference to it.
+func (q *Rat) doinit() {
+	if q.init {
+		q.copyCheck()
+		return
+	}
+	q.init = true
+	q.addr = q
+	q.i.SetInt64(0)
+	trackInit(unsafe.Pointer(q), "Rat")
+	trackCollect(unsafe.Pointer(q))
+}
+
+// copyCheck panics if q is an initialized Rat that was copied by value.
+func (q *Rat) copyCheck() {
+	if q.addr != q {
+		panic("gmp: illegal use of non-zero Rat copied by value")
+	}
+}
+
+// Set sets z = x and returns z.
+func (q *Rat) Set(x *Rat) *Rat {
+	x.doinit()
+	q.doinit()
+	q.i.Set(&x.i)
+	return q
+}
+
+// SetFrac64 sets q to x/y and returns q.
+func (q *Rat) SetFrac64(x int64, y int64) *Rat {
+	q.doinit()
+	q.i.SetFrac64(x, y)
+	return q
+}
+
+// SetInt64 sets z to x and returns z.
+func (z *Rat) SetInt64(x int64) *Rat {
+	z.doinit()
+	z.i.SetInt64(x)
+	return z
+}
+
+// SetUint sets q to x/y and returns q.
+func (q *Rat) SetUint(x, y uint) *Rat {
+	q.doinit()
+	q.i.SetFrac(new(big.Int).SetUint64(uint64(x)), new(big.Int).SetUint64(uint64(y)))
+	return q
+}
+
+// SetFrac sets z to a/b and returns z.
+func (z *Rat) SetFrac(a, b *Int) *Rat {
+	a.doinit()
+	b.doinit()
+	z.doinit()
+	z.i.SetFrac(a.ptr, b.ptr)
+	return z
+}
+
+// SetInt sets q to x and returns q.
+func (q *Rat) SetInt(x *Int) *Rat {
+	q.doinit()
+	x.doinit()
+	q.i.SetInt(x.ptr)
+	return q
+}
+
+// SetStringBase interprets s as a number in the given base
+// and sets z to that value.  The base must be in the range [2,MaxBase].
+// SetString returns an error if s cannot be parsed or the base is invalid.
+func (q *Rat) SetStringBase(s string, base int) (*Rat, bool) {
+	q.doinit()
+	if base < 2 || base > MaxBase {
+		return nil, false
+	}
+	num, denom, frac := strings.Cut(s, "/")
+	var a, b big.Int
+	if !setIntString(&a, num, base) {
+		return nil, false
+	}
+	b.SetInt64(1)
+	if frac && (!setIntString(&b, denom, base) || b.Sign() == 0) {
+		return nil, false
+	}
+	q.i.SetFrac(&a, &b)
+	return q, true
+}
+
+func SwapRat(x, y *Rat) {
+	x.doinit()
+	y.doinit()
+	x.i, y.i = y.i, x.i
+}
+
+// String returns the representation of z in the given base.
+func (q *Rat) StringBase(base int) (string, error) {
+	if q == nil {
+		return "nil", nil
+	}
+	if base < 2 || base > MaxBase {
+		return "", os.ErrInvalid
+	}
+	q.doinit()
+	s := text(q.i.Num(), base)
+	if !q.i.IsInt() {
+		s += "/" + text(q.i.Denom(), base)
+	}
+	return s, nil
+}
+
+// RatString returns a string representation of z in the form "a/b" if b != 1,
+// and in the form "a" if b == 1.
+func (q *Rat) RatString() string {
+	q.doinit()
+	s, _ := q.StringBase(10)
+	return s
+}
+
+// String returns a string representation of z in the form "a/b"
+// (even if b == 1).
+func (q *Rat) String() string {
+	q.doinit()
+	s := q.RatString()
+	if q.IsInt() { // s not in the form a/b
+		s = s + "/1"
+	}
+	return s
+}
+
+// Float64 returns x truncated to a float64.
+func (q *Rat) Float64() float64 {
+	q.doinit()
+	f, _ := new(big.Float).SetPrec(53).SetMode(big.ToZero).SetRat(&q.i).Float64()
+	return f
+}
+
+// SetFloat64 sets f = x and returns q.
+func (q *Rat) SetFloat64(x float64) *Rat {
+	q.doinit()
+	q.i.SetFloat64(x)
+	return q
+}
+
+// SetFloat sets f = x and returns f.
+func (q *Rat) SetFloat(x *Float) *Rat {
+	x.doinit()
+	q.doinit()
+	x.i.Rat(&q.i)
+	return q
+}
+
+func (q *Rat) destroy() {
+	if q.init {
+		q.copyCheck()
+		q.i = big.Rat{}
+		trackClear(unsafe.Pointer(q))
+	}
+	q.init = false
+}
+
+// Clear releases the numerator and denominator of q. Calling Clear is
+// optional: without cgo the memory is managed by the garbage collector. It
+// is safe to call Clear more than once, and q may be reused afterwards; it
+// then holds the value 0.
+func (q *Rat) Clear() {
+	q.destroy()
+}
+
+// Add sets z to the sum x+y and returns z.
+func (q *Rat) Add(x, y *Rat) *Rat {
+	x.doinit()
+	y.doinit()
+	q.doinit()
+	q.i.Add(&x.i, &y.i)
+	return q
+}
+
+func (q *Rat) Sub(x, y *Rat) *Rat {
+	x.doinit()
+	y.doinit()
+	q.doinit()
+	q.i.Sub(&x.i, &y.i)
+	return q
+}
+
+func (q *Rat) Mul(x, y *Rat) *Rat {
+	x.doinit()
+	y.doinit()
+	q.doinit()
+	q.i.Mul(&x.i, &y.i)
+	return q
+}
+
+// Neg sets z to -x and returns z.
+func (z *Rat) Neg(x *Rat) *Rat {
+	z.doinit()
+	x.doinit()
+	z.i.Neg(&x.i)
+	return z
+}
+
+func (q *Rat) Quo(x, y *Rat) *Rat {
+	x.doinit()
+	y.doinit()
+	q.doinit()
+	q.i.Quo(&x.i, &y.i)
+	return q
+}
+
+// Abs sets z to |x| (the absolute value of x) and returns z.
+func (q *Rat) Abs(x *Rat) *Rat {
+	x.doinit()
+	q.doinit()
+	q.i.Abs(&x.i)
+	return q
+}
+
+func (q *Rat) Inv(x *Rat) *Rat {
+	x.doinit()
+	q.doinit()
+	q.i.Inv(&x.i)
+	return q
+}
+
+// Mul2Exp sets z = x * 2^s and returns z.
+func (q *Rat) Mul2Exp(x *Rat, s uint) *Rat {
+	x.doinit()
+	q.doinit()
+	q.i.SetFrac(new(big.Int).Lsh(x.i.Num(), s), x.i.Denom())
+	return q
+}
+
+// Div2Exp sets z = x / 2^s and returns z.
+func (q *Rat) Div2Exp(x *Rat, s uint) *Rat {
+	x.doinit()
+	q.doinit()
+	q.i.SetFrac(x.i.Num(), new(big.Int).Lsh(x.i.Denom(), s))
+	return q
+}
+
+// Cmp compares x and y and returns:
+//
+//	-1 if x <  y
+//	 0 if x == y
+//	+1 if x >  y
+func (x *Rat) Cmp(y *Rat) int {
+	x.doinit()
+	y.doinit()
+	return x.i.Cmp(&y.i)
+}
+
+func CmpRatUint(q *Rat, x, y uint) int {
+	q.doinit()
+	return 0 // FIXME(ug): Macro...
+}
+
+func CmpRatInt64(q *Rat, x int64, y uint) int {
+	q.doinit()
+	return 0 // FIXME(ug): Macro...
+}
+
+// IsInt returns true if the denominator of x is 1.
+func (q *Rat) IsInt() bool {
+	q.doinit()
+	return q.i.IsInt()
+}
+
+// Sign returns:
+//
+//	-1 if x <  0
+//	 0 if x == 0
+//	+1 if x >  0
+func (x *Rat) Sign() int {
+	x.doinit()
+	return x.i.Sign()
+}
+
+func EqRat(x, y *Rat) bool {
+	x.doinit()
+	y.doinit()
+	return x.i.Cmp(&y.i) == 0
+}
+
+// Num returns the numerator of x; it may be <= 0. The result is a reference
+// to x's numerator; it may change if a new value is assigned to x, and vice
+// versa. The sign of the numerator corresponds to the sign of x.
+func (q *Rat) Num() *Int {
+	q.doinit()
+	n := new(Int)
+	n.init = true
+	n.addr = n
+	n.ptr = q.i.Num()
+	return n
+}
+
+// Denom returns the denominator of x; it is always > 0. The result is a
+// reference to x's denominator; it may change if a new value is assigned to
+// x, and vice versa.
+func (q *Rat) Denom() *Int {
+	q.doinit()
+	n := new(Int)
+	n.init = true
+	n.addr = n
+	n.ptr = q.i.Denom()
+	return n
+}
@@ -1,6 +1,13 @@
 package gmp
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
 	"testing"
 )
 
@@ -77,6 +84,19 @@ var setStringTests = []struct {
 	{"53/70893980658822810696", "53/70893980658822810696", true},
 	{"106/141787961317645621392", "53/70893980658822810696", true},
 	{"204211327800791583.81095", "4084226556015831676219/20000", true},
+	{"-12.375e-4", "-99/80000", true},
+	{in: "1/2e3", ok: false},
+	{in: "0x10", ok: false},
+	{"+3/4", "3/4", true},
+	{in: "3/ 4", ok: false},
+	{in: "3 /4", ok: false},
+	{in: " 3/4", ok: false},
+	{in: "1/-2", ok: false},
+	{in: "1/+2", ok: false},
+	{in: "--1/2", ok: false},
+	{in: "/2", ok: false},
+	{in: "1/", ok: false},
+	{in: "1/2/3", ok: false},
 }
 
 func TestRatSetString(t *testing.T) {
@@ -91,6 +111,42 @@ func TestRatSetString(t *testing.T) {
 			}
 		} else if x != nil {
 			t.Errorf("#%d SetString(%q) got %p want nil", i, test.in, x)
+		} else if test.ok {
+			t.Errorf("#%d SetString(%q) failed", i, test.in)
+		}
+	}
+}
+
+var floatStringTests = []struct {
+	in   string
+	prec int
+	out  string
+}{
+	{"0", 0, "0"},
+	{"0", 4, "0.0000"},
+	{"1", 0, "1"},
+	{"1", 2, "1.00"},
+	{"-1", 0, "-1"},
+	{"0.05", 1, "0.1"},
+	{"-0.05", 1, "-0.1"},
+	{".25", 2, "0.25"},
+	{".25", 1, "0.3"},
+	{".25", 3, "0.250"},
+	{"-1/3", 3, "-0.333"},
+	{"-2/3", 4, "-0.6667"},
+	{"0.96", 1, "1.0"},
+	{"0.999", 2, "1.00"},
+	{"0.9", 0, "1"},
+	{".25", -1, "0"},
+	{".55", -1, "1"},
+}
+
+func TestFloatString(t *testing.T) {
+	for i, test := range floatStringTests {
+		x, _ := new(Rat).SetString(test.in)
+
+		if x.FloatString(test.prec) != test.out {
+			t.Errorf("#%d got %s want %s", i, x.FloatString(test.prec), test.out)
 		}
 	}
 }
@@ -283,6 +339,8 @@ var getStringTests = []struct {
 	{"-1/1", "-1/1", true},
 	{"2/1", "2/1", true},
 	{"4/2", "2/1", true},
+	{"-10", "-10/1", true},
+	{"1.25", "5/4", true},
 }
 
 func TestGetString(t *testing.T) {
@@ -344,3 +402,255 @@ func TestSetInt64(t *testing.T) {
 		}
 	}
 }
+
+func TestRatClear(t *testing.T) {
+	q := NewRat(3, 4)
+	n := q.Num()
+	n.Clear() // a reference must not free q's numerator
+	if s := q.String(); s != "3/4" {
+		t.Errorf("got %s after clearing Num(); want 3/4", s)
+	}
+	q.Clear()
+	q.Clear()
+	if s := q.String(); s != "0/1" {
+		t.Errorf("got %s after Clear; want 0/1", s)
+	}
+}
+
+func TestRatInStruct(t *testing.T) {
+	p := new(struct{ a, b Rat })
+	p.b.SetFrac64(1, 3)
+	s := make([]Rat, 3)
+	s[1].SetFrac64(1, 6)
+	runtime.GC()
+	p.a.Add(&p.b, &s[1])
+	if got := p.a.String(); got != "1/2" {
+		t.Errorf("got %s; want 1/2", got)
+	}
+	// A zero value is a valid source too.
+	if p.a.Set(&s[2]).Sign() != 0 {
+		t.Errorf("Set(zero value) = %s; want 0", &p.a)
+	}
+}
+
+func TestNumOutlivesRat(t *testing.T) {
+	// A reference keeps the Rat it belongs to alive.
+	n, d := NewRat(3, 4).Num(), NewRat(3, 4).Denom()
+	runtime.GC()
+	runtime.GC()
+	if n.Int64() != 3 || d.Int64() != 4 {
+		t.Errorf("got %s and %s; want 3 and 4", n, d)
+	}
+}
+
+func TestRatCopyCheck(t *testing.T) {
+	x := NewRat(1, 2)
+	var y Rat
+	copyValue(&y, x)
+	expectCopyPanic(t, "Add", func() { y.Add(&y, x) })
+	expectCopyPanic(t, "Set", func() { new(Rat).Set(&y) })
+	expectCopyPanic(t, "Clear", func() { y.Clear() })
+
+	// Num and Denom are references, not copies.
+	x.Num().SetInt64(3)
+	if s := x.String(); s != "3/2" {
+		t.Errorf("got %s; want 3/2", s)
+	}
+}
+
+func TestRatScan(t *testing.T) {
+	for i, test := range []struct {
+		input, format, output string
+		remaining             int
+	}{
+		{"1/2", "%v", "1/2", 0},
+		{"-6/4", "%d", "-3/2", 0},
+		{"+7", "%v", "7/1", 0},
+		{"a -", "%x", "10/1", 2},
+		{"ff/10 x", "%x", "255/16", 2},
+		{"101/11", "%b", "5/3", 0},
+		{"3/4/5", "%v", "3/4", 2},
+	} {
+		x := new(Rat)
+		buf := bytes.NewBufferString(test.input)
+		if _, err := fmt.Fscanf(buf, test.format, x); err != nil {
+			t.Errorf("#%d error: %s", i, err)
+		}
+		if x.String() != test.output {
+			t.Errorf("#%d got %s; want %s", i, x.String(), test.output)
+		}
+		if buf.Len() != test.remaining {
+			t.Errorf("#%d got %d bytes remaining; want %d", i, buf.Len(), test.remaining)
+		}
+	}
+
+	for _, input := range []string{"", "/2", "1/", "1/0", "-"} {
+		if _, err := fmt.Sscan(input, new(Rat)); err == nil {
+			t.Errorf("Sscan(%q) succeeded", input)
+		}
+	}
+}
+
+func TestRatMarshalText(t *testing.T) {
+	for _, test := range []string{"0", "3", "-1/2", "12345678901234567890/98765432109876543211"} {
+		x, _ := new(Rat).SetString(test)
+		text, err := x.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(text) != test {
+			t.Errorf("MarshalText(%s) = %s", test, text)
+		}
+		var y Rat
+		if err := y.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		if y.Cmp(x) != 0 {
+			t.Errorf("UnmarshalText(%s) = %s", text, &y)
+		}
+	}
+
+	if err := new(Rat).UnmarshalText([]byte("1/0")); err == nil {
+		t.Error("UnmarshalText(1/0) succeeded")
+	}
+}
+
+func TestRatJSON(t *testing.T) {
+	b, err := json.Marshal([]*Rat{NewRat(-1, 2), NewRat(3, 1), nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["-1/2","3",null]`; string(b) != want {
+		t.Errorf("got %s; want %s", b, want)
+	}
+
+	var v []*Rat
+	if err := json.Unmarshal([]byte(`["-1/2", 1.25, "2e-3", 7, null]`), &v); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-1/2", "5/4", "1/500", "7", "<nil>"}
+	for i, x := range v {
+		got := "<nil>"
+		if x != nil {
+			got = x.RatString()
+		}
+		if got != want[i] {
+			t.Errorf("#%d got %s; want %s", i, got, want[i])
+		}
+	}
+
+	if err := new(Rat).UnmarshalJSON([]byte(`"0x10"`)); err == nil {
+		t.Error("UnmarshalJSON(\"0x10\") succeeded")
+	}
+}
+
+func TestRatBinary(t *testing.T) {
+	for _, test := range []string{"0", "-3", "1/2", "-12345678901234567890/98765432109876543211"} {
+		x, _ := new(Rat).SetString(test)
+		b, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var y Rat
+		if err := y.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if y.Cmp(x) != 0 {
+			t.Errorf("%s: decoded %s", test, &y)
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(x); err != nil {
+			t.Fatal(err)
+		}
+		var z Rat
+		if err := gob.NewDecoder(&buf).Decode(&z); err != nil {
+			t.Fatal(err)
+		}
+		if z.Cmp(x) != 0 {
+			t.Errorf("%s: gob decoded %s", test, &z)
+		}
+	}
+
+	for _, b := range [][]byte{nil, {2, 0, 0, 0, 0}, {2, 0, 0, 0, 9}, {4, 0, 0, 0, 0}} {
+		if err := new(Rat).UnmarshalBinary(b); err == nil {
+			t.Errorf("UnmarshalBinary(%x) succeeded", b)
+		}
+	}
+}
+
+func TestRatRaw(t *testing.T) {
+	var buf bytes.Buffer
+	x := NewRat(-3, 258)
+	if _, err := x.WriteRaw(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// -1/86 after canonicalization
+	if got, want := hex.EncodeToString(buf.Bytes()), "ffffffff010000000156"; got != want {
+		t.Errorf("WriteRaw(%s) = %s; want %s", x, got, want)
+	}
+	var y Rat
+	if _, err := y.ReadRaw(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if y.Cmp(x) != 0 {
+		t.Errorf("ReadRaw = %s; want %s", &y, x)
+	}
+
+	if _, err := y.ReadRaw(bytes.NewReader([]byte{0, 0, 0, 1, 1, 0, 0, 0, 0})); err == nil {
+		t.Error("ReadRaw with zero denominator succeeded")
+	}
+	if _, err := y.ReadRaw(bytes.NewReader([]byte{0, 0, 0, 1, 1})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadRaw without denominator returned %v; want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestRatCBOR(t *testing.T) {
+	for _, test := range []struct {
+		x    string
+		cbor string
+	}{
+		{"0", "d81e820001"},
+		{"5", "d81e820501"},
+		{"1/3", "d81e820103"},
+		{"-1/2", "d81e822002"},
+		{"1/18446744073709551616", "d81e8201c249010000000000000000"},
+	} {
+		x, _ := new(Rat).SetString(test.x)
+		data, err := x.MarshalCBOR()
+		if err != nil || hex.EncodeToString(data) != test.cbor {
+			t.Errorf("MarshalCBOR(%s) = %x, %v; want %s", test.x, data, err, test.cbor)
+		}
+		var y Rat
+		if err := y.UnmarshalCBOR(data); err != nil || y.Cmp(x) != 0 {
+			t.Errorf("UnmarshalCBOR(%s) = %s, %v; want %s", test.cbor, &y, err, test.x)
+		}
+	}
+
+	// Other numbers are read exactly too.
+	for _, test := range []struct {
+		cbor string
+		x    string
+	}{
+		{"05", "5"},
+		{"c349010000000000000000", "-18446744073709551617"},
+		{"c48221196ab3", "273.15"},
+		{"c5822003", "1.5"},
+		{"c5820203", "12"},
+		{"d81e820204", "1/2"},
+	} {
+		data, _ := hex.DecodeString(test.cbor)
+		want, _ := new(Rat).SetString(test.x)
+		var x Rat
+		if err := x.UnmarshalCBOR(data); err != nil || x.Cmp(want) != 0 {
+			t.Errorf("UnmarshalCBOR(%s) = %s, %v; want %s", test.cbor, &x, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "d81e", "d81e8101", "d81e820100", "d81e820120", "d81e83010203", "c48200", "c5821a3b9aca0001", "d81f00", "d81e82010200"} {
+		data, _ := hex.DecodeString(s)
+		if err := new(Rat).UnmarshalCBOR(data); err == nil {
+			t.Errorf("UnmarshalCBOR(%s) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package gmp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// SetString sets z to the value of s and returns z and a boolean indicating
+// success. s can be given as a fraction "a/b" or as a decimal number
+// optionally followed by an exponent, like "-12.375e-4". If the operation
+// failed, the value of z is undefined but the returned value is nil.
+func (z *Rat) SetString(s string) (*Rat, bool) {
+	if a, b, ok := strings.Cut(s, "/"); ok {
+		// mpq_set_str also accepts whitespace and a signed denominator,
+		// which math/big rejects, but no "+" sign.
+		if len(a) > 0 && (a[0] == '+' || a[0] == '-') {
+			a = a[1:]
+		}
+		if !isDigits(a) || !isDigits(b) {
+			return nil, false
+		}
+		return z.SetStringBase(strings.TrimPrefix(s, "+"), 10)
+	}
+	r, ok := parseDecimal(s)
+	if !ok {
+		return nil, false
+	}
+	return z.SetBig(r), true
+}
+
+// isDigits reports whether s is a nonempty string of decimal digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDecimal returns the exact value of s, a decimal number with an
+// optional fraction and exponent like "-1.25e-3".
+func parseDecimal(s string) (*big.Rat, bool) {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789+-.eE", c) {
+			return nil, false
+		}
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// FloatString returns a string representation of x in decimal form with
+// prec digits of precision after the radix point. The last digit is
+// rounded to nearest, with halves rounded away from zero.
+func (x *Rat) FloatString(prec int) string {
+	var buf []byte
+
+	if x.IsInt() {
+		buf = append(buf, x.Num().String()...)
+		if prec > 0 {
+			buf = append(buf, '.')
+			for i := prec; i > 0; i-- {
+				buf = append(buf, '0')
+			}
+		}
+		return string(buf)
+	}
+	// x.Denom() != 0
+
+	num := new(Int).Abs(x.Num())
+	denom := x.Denom()
+	q, r := new(Int), new(Int)
+	defer num.Clear()
+	defer q.Clear()
+	defer r.Clear()
+	q.QuoRem(num, denom, r)
+
+	p := NewInt(1)
+	defer p.Clear()
+	if prec > 0 {
+		p.Exp(NewInt(10), NewInt(int64(prec)), nil)
+	}
+
+	r.Mul(r, p)
+	r2 := new(Int)
+	defer r2.Clear()
+	r.QuoRem(r, denom, r2)
+
+	// see if we need to round up
+	r2.Add(r2, r2)
+	if denom.Cmp(r2) <= 0 {
+		r.Add(r, intOne)
+		if r.Cmp(p) >= 0 {
+			q.Add(q, intOne)
+			r.Sub(r, p)
+		}
+	}
+
+	if x.Sign() < 0 {
+		buf = append(buf, '-')
+	}
+	buf = append(buf, q.String()...)
+
+	if prec > 0 {
+		buf = append(buf, '.')
+		rs := r.String()
+		for i := prec - len(rs); i > 0; i-- {
+			buf = append(buf, '0')
+		}
+		buf = append(buf, rs...)
+	}
+
+	return string(buf)
+}
+
+// Scan is a support routine for fmt.Scanner. It sets q to the value of a
+// scanned fraction "a/b" or integer "a", with a and b in the base given by
+// the verb: 'b' (binary), 'o' (octal), 'd', 's' or 'v' (decimal), 'x' or
+// 'X' (hexadecimal).
+func (q *Rat) Scan(s fmt.ScanState, ch rune) error {
+	s.SkipSpace() // skip leading space characters
+	var base int
+	switch ch {
+	case 'b':
+		base = 2
+	case 'o':
+		base = 8
+	case 'd', 's', 'v':
+		base = 10
+	case 'x', 'X':
+		base = 16
+	default:
+		return errors.New("gmp: Rat.Scan: invalid verb")
+	}
+	buf, _, err := scanInt(s, base)
+	if err != nil {
+		return err
+	}
+	if ch, _, err := s.ReadRune(); err == nil {
+		if ch == '/' {
+			buf = append(buf, '/')
+			if buf, err = scanDigits(s, base, buf); err != nil {
+				return err
+			}
+		} else {
+			s.UnreadRune()
+		}
+	} else if err != io.EOF {
+		return err
+	}
+	if _, ok := q.SetStringBase(string(buf), base); !ok {
+		return errors.New("gmp: Rat.Scan: invalid syntax")
+	}
+	return nil
+}
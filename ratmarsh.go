@@ -0,0 +1,122 @@
+package gmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Gob codec version. Permits backward-compatible changes to the encoding.
+const ratGobVersion byte = 1
+
+// MarshalText implements the encoding.TextMarshaler interface. The result
+// has the form "a/b", or "a" if x is an integer, like RatString.
+func (x *Rat) MarshalText() (text []byte, err error) {
+	if x == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(x.RatString()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (z *Rat) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text)); !ok {
+		return fmt.Errorf("gmp: cannot unmarshal %q into a *gmp.Rat", text)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. x is written as a
+// JSON string in the form of MarshalText since a fraction has no JSON
+// number representation. A nil x is written as null.
+func (x *Rat) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	text, _ := x.MarshalText()
+	return marshalJSON(text, true), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts
+// JSON strings and numbers in the form accepted by SetString. null leaves
+// z unchanged, so a *Rat decoded from null stays nil.
+func (z *Rat) UnmarshalJSON(data []byte) error {
+	text, null, err := unmarshalJSON(data)
+	if err != nil || null {
+		return err
+	}
+	if _, ok := z.SetString(string(text)); !ok {
+		return fmt.Errorf("gmp: cannot unmarshal %s into a *gmp.Rat", data)
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The
+// encoding is a version and sign byte, the length in bytes of the
+// numerator as a big-endian uint32, and then the magnitudes of the
+// numerator and denominator in 64-bit limbs.
+func (x *Rat) MarshalBinary() ([]byte, error) {
+	b := ratGobVersion << 1 // make space for sign bit
+	if x.Sign() < 0 {
+		b |= 1
+	}
+	buf := make([]byte, 5, 5+2*limbBytes)
+	buf[0] = b
+	buf = appendLimbs(buf, x.Num())
+	n := len(buf) - 5
+	if int(uint32(n)) != n {
+		return nil, errors.New("gmp: Rat.MarshalBinary: numerator too large")
+	}
+	binary.BigEndian.PutUint32(buf[1:5], uint32(n))
+	return appendLimbs(buf, x.Denom()), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (z *Rat) UnmarshalBinary(buf []byte) error {
+	if len(buf) < 5 {
+		return errors.New("gmp: Rat.UnmarshalBinary: buffer too small")
+	}
+	b := buf[0]
+	if b>>1 != ratGobVersion {
+		return fmt.Errorf("gmp: Rat.UnmarshalBinary: encoding version %d not supported", b>>1)
+	}
+	n := uint64(binary.BigEndian.Uint32(buf[1:5]))
+	if n > uint64(len(buf)-5) {
+		return errors.New("gmp: Rat.UnmarshalBinary: buffer too small")
+	}
+	num, denom := new(Int), new(Int)
+	defer num.Clear()
+	defer denom.Clear()
+	if err := setLimbs(num, buf[5:5+n]); err != nil {
+		return fmt.Errorf("gmp: Rat.UnmarshalBinary: %v", err)
+	}
+	if err := setLimbs(denom, buf[5+n:]); err != nil {
+		return fmt.Errorf("gmp: Rat.UnmarshalBinary: %v", err)
+	}
+	if denom.Sign() == 0 {
+		return errors.New("gmp: Rat.UnmarshalBinary: zero denominator")
+	}
+	if b&1 != 0 {
+		num.Neg(num)
+	}
+	z.SetFrac(num, denom)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (x *Rat) GobEncode() ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return x.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *Rat) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		// Other side sent a nil or default value.
+		z.SetInt64(0)
+		return nil
+	}
+	return z.UnmarshalBinary(buf)
+}
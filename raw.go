@@ -0,0 +1,100 @@
+package gmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// WriteRaw writes x to w in gmp's portable raw format, byte for byte like
+// mpz_out_raw: the size of the magnitude in bytes as a 4-byte big-endian
+// two's complement number, negated if x is negative, followed by the
+// magnitude in big-endian order. WriteRaw returns the number of bytes
+// written.
+func (x *Int) WriteRaw(w io.Writer) (int, error) {
+	mag := x.Bytes()
+	if len(mag) > math.MaxInt32 {
+		return 0, errors.New("gmp: Int.WriteRaw: value too large")
+	}
+	size := int32(len(mag))
+	if x.Sign() < 0 {
+		size = -size
+	}
+	buf := make([]byte, 4, 4+len(mag))
+	binary.BigEndian.PutUint32(buf, uint32(size))
+	return w.Write(append(buf, mag...))
+}
+
+// ReadRaw sets z to a value read from r in the format written by WriteRaw
+// and mpz_out_raw, and returns the number of bytes read.
+func (z *Int) ReadRaw(r io.Reader) (int, error) {
+	var hdr [4]byte
+	if n, err := io.ReadFull(r, hdr[:]); err != nil {
+		return n, err
+	}
+	size := int64(int32(binary.BigEndian.Uint32(hdr[:])))
+	neg := size < 0
+	if neg {
+		size = -size
+	}
+	mag, err := readFull(r, size)
+	if err != nil {
+		return 4 + len(mag), err
+	}
+	z.SetBytes(mag)
+	if neg {
+		z.Neg(z)
+	}
+	return 4 + len(mag), nil
+}
+
+// readFull reads n bytes from r, which must not end before. Unlike
+// io.ReadFull it doesn't trust n with a large allocation before the data
+// arrives.
+func readFull(r io.Reader, n int64) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := io.CopyN(&buf, r, n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+// WriteRaw writes x to w as its numerator followed by its denominator,
+// each in the format of Int.WriteRaw. It returns the number of bytes
+// written.
+func (x *Rat) WriteRaw(w io.Writer) (int, error) {
+	n, err := x.Num().WriteRaw(w)
+	if err != nil {
+		return n, err
+	}
+	m, err := x.Denom().WriteRaw(w)
+	return n + m, err
+}
+
+// ReadRaw sets z to a value read from r in the format written by
+// Rat.WriteRaw, and returns the number of bytes read. The value is
+// canonicalized; a zero denominator is an error.
+func (z *Rat) ReadRaw(r io.Reader) (int, error) {
+	num, denom := new(Int), new(Int)
+	defer num.Clear()
+	defer denom.Clear()
+	n, err := num.ReadRaw(r)
+	if err != nil {
+		return n, err
+	}
+	m, err := denom.ReadRaw(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return n + m, err
+	}
+	if denom.Sign() == 0 {
+		return n + m, errors.New("gmp: Rat.ReadRaw: zero denominator")
+	}
+	z.SetFrac(num, denom)
+	return n + m, nil
+}
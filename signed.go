@@ -0,0 +1,63 @@
+package gmp
+
+// SignedBytes returns x as a big-endian two's complement byte slice of
+// minimal length, whose first bit is the sign bit, like Java's
+// BigInteger.toByteArray. 0 is returned as a single zero byte.
+func (x *Int) SignedBytes() []byte {
+	if x.Sign() >= 0 {
+		b := x.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// -x-1 has the bits of x inverted.
+	n := new(Int).Neg(x)
+	n.Sub(n, intOne)
+	b := n.Bytes()
+	n.Clear()
+	for i := range b {
+		b[i] ^= 0xff
+	}
+	if len(b) == 0 || b[0]&0x80 == 0 {
+		b = append([]byte{0xff}, b...)
+	}
+	return b
+}
+
+// SetSignedBytes interprets buf as a big-endian two's complement number,
+// sets z to that value, and returns z. buf may be sign-extended to any
+// length, like the output of FillSignedBytes; an empty buf is 0.
+func (z *Int) SetSignedBytes(buf []byte) *Int {
+	if len(buf) == 0 || buf[0]&0x80 == 0 {
+		return z.SetBytes(buf)
+	}
+	inv := make([]byte, len(buf))
+	for i, c := range buf {
+		inv[i] = c ^ 0xff
+	}
+	z.SetBytes(inv)
+	z.Add(z, intOne)
+	return z.Neg(z)
+}
+
+// FillSignedBytes sets buf to x, storing it as a sign-extended big-endian
+// two's complement byte slice, and returns buf.
+//
+// If x doesn't fit in buf, FillSignedBytes will panic.
+func (x *Int) FillSignedBytes(buf []byte) []byte {
+	b := x.SignedBytes()
+	if len(b) > len(buf) {
+		panic("gmp: buffer too small to fit value")
+	}
+	ext := byte(0)
+	if x.Sign() < 0 {
+		ext = 0xff
+	}
+	n := len(buf) - len(b)
+	for i := range buf[:n] {
+		buf[i] = ext
+	}
+	copy(buf[n:], b)
+	return buf
+}
@@ -0,0 +1,213 @@
+package gmp
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+)
+
+// Int, Rat and Float implement fmt.Scanner, whose Scan method has a
+// different signature than the one of sql.Scanner. SQLInt, SQLRat and
+// SQLFloat adapt them, in the style of sql.NullString:
+//
+//	var v gmp.SQLInt
+//	err := row.Scan(&v) // v.Int is nil if the column was NULL
+//
+// or, to scan into an existing value,
+//
+//	err := row.Scan(&gmp.SQLInt{Int: x})
+//
+// The types themselves implement driver.Valuer, so they can be passed
+// as query arguments directly.
+
+// SQLInt is an *Int that implements sql.Scanner. A nil Int stands for
+// NULL.
+type SQLInt struct {
+	Int *Int
+}
+
+// Scan implements the sql.Scanner interface. It accepts integers stored as
+// int64, float64 and decimal text, such as NUMERIC columns, as long as they
+// have no fractional part. If s.Int is nil, Scan allocates a new Int.
+func (s *SQLInt) Scan(src any) error {
+	if src == nil {
+		s.Int = nil
+		return nil
+	}
+	r, err := scanNumeric(src, "Int")
+	if err != nil {
+		return err
+	}
+	if !r.IsInt() {
+		return fmt.Errorf("gmp: cannot scan non-integer %s into a *gmp.Int", r.FloatString(10))
+	}
+	if s.Int == nil {
+		s.Int = new(Int)
+	}
+	s.Int.SetBig(r.Num())
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (s SQLInt) Value() (driver.Value, error) {
+	if s.Int == nil {
+		return nil, nil
+	}
+	return s.Int.Value()
+}
+
+// Value implements the driver.Valuer interface. It returns x as decimal
+// text, or nil for a nil x.
+func (x *Int) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+	return x.String(), nil
+}
+
+// SQLRat is a *Rat that implements sql.Scanner. A nil Rat stands for
+// NULL.
+type SQLRat struct {
+	Rat *Rat
+}
+
+// Scan implements the sql.Scanner interface. It accepts int64, float64,
+// decimal text such as NUMERIC columns, and fractions "a/b". The value is
+// converted exactly. If s.Rat is nil, Scan allocates a new Rat.
+func (s *SQLRat) Scan(src any) error {
+	if src == nil {
+		s.Rat = nil
+		return nil
+	}
+	if s.Rat == nil {
+		s.Rat = new(Rat)
+	}
+	if str, ok := src.(string); ok {
+		src = []byte(str)
+	}
+	if b, ok := src.([]byte); ok {
+		if _, ok := s.Rat.SetString(string(b)); ok {
+			return nil
+		}
+	}
+	r, err := scanNumeric(src, "Rat")
+	if err != nil {
+		return err
+	}
+	s.Rat.SetBig(r)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (s SQLRat) Value() (driver.Value, error) {
+	if s.Rat == nil {
+		return nil, nil
+	}
+	return s.Rat.Value()
+}
+
+// Value implements the driver.Valuer interface. It returns x as exact
+// decimal text, or an error if x has no finite decimal representation,
+// like 1/3. A nil x returns nil.
+func (x *Rat) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+	s, ok := decimalString(x.Big())
+	if !ok {
+		return nil, fmt.Errorf("gmp: %s has no exact decimal representation", x.RatString())
+	}
+	return s, nil
+}
+
+// SQLFloat is a *Float that implements sql.Scanner. A nil Float stands
+// for NULL.
+type SQLFloat struct {
+	Float *Float
+}
+
+// Scan implements the sql.Scanner interface. It accepts int64, float64 and
+// decimal text such as NUMERIC columns. The value is truncated to the
+// precision of s.Float like with UnmarshalText, unless s.Float can hold it
+// exactly. If s.Float is nil, Scan allocates a new Float with the default
+// precision.
+func (s *SQLFloat) Scan(src any) error {
+	if src == nil {
+		s.Float = nil
+		return nil
+	}
+	r, err := scanNumeric(src, "Float")
+	if err != nil {
+		return err
+	}
+	if s.Float == nil {
+		s.Float = new(Float)
+	}
+	s.Float.setTruncated(r)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (s SQLFloat) Value() (driver.Value, error) {
+	if s.Float == nil {
+		return nil, nil
+	}
+	return s.Float.Value()
+}
+
+// Value implements the driver.Valuer interface. It returns the exact
+// value of x as decimal text, without exponent. A nil x returns nil.
+func (x *Float) Value() (driver.Value, error) {
+	if x == nil {
+		return nil, nil
+	}
+	r, _ := x.exact().Rat(nil)
+	s, _ := decimalString(r)
+	return s, nil
+}
+
+// scanNumeric returns the exact value of src, which a database driver
+// returned for a numeric column, for scanning into a value of type typ.
+func scanNumeric(src any, typ string) (*big.Rat, error) {
+	switch src := src.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(src), nil
+	case float64:
+		if r := new(big.Rat).SetFloat64(src); r != nil {
+			return r, nil
+		}
+	case []byte:
+		if r, ok := parseDecimal(string(src)); ok {
+			return r, nil
+		}
+	case string:
+		if r, ok := parseDecimal(src); ok {
+			return r, nil
+		}
+	default:
+		return nil, fmt.Errorf("gmp: cannot scan %T into a *gmp.%s", src, typ)
+	}
+	return nil, fmt.Errorf("gmp: cannot scan %v into a *gmp.%s", src, typ)
+}
+
+// decimalString returns r as exact decimal text without exponent, and
+// false if its denominator has prime factors other than 2 and 5.
+func decimalString(r *big.Rat) (string, bool) {
+	d := new(big.Int).Set(r.Denom())
+	twos := d.TrailingZeroBits()
+	d.Rsh(d, twos)
+	fives := uint(0)
+	five, m := big.NewInt(5), new(big.Int)
+	for d.Cmp(five) >= 0 {
+		q, _ := new(big.Int).QuoRem(d, five, m)
+		if m.Sign() != 0 {
+			break
+		}
+		d = q
+		fives++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	return r.FloatString(int(max(twos, fives))), true
+}
@@ -0,0 +1,175 @@
+package gmp
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeDriver is an in-memory database/sql driver with a single table
+// that holds one column. "INSERT" appends its argument as a row, "SELECT"
+// returns all rows, with strings turned into []byte like real drivers
+// return NUMERIC columns.
+type fakeDriver struct {
+	rows []driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s fakeStmt) Close() error { return nil }
+
+func (s fakeStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+	return 0
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.rows = append(s.d.rows, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{rows: s.d.rows}, nil
+}
+
+type fakeRows struct {
+	rows []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"n"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	v := r.rows[0]
+	if s, ok := v.(string); ok {
+		v = []byte(s)
+	}
+	dest[0] = v
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fakeDB = &fakeDriver{}
+
+func init() {
+	sql.Register("gmpfake", fakeDB)
+}
+
+// roundTrip inserts the values into an empty fake table and returns a
+// query for them.
+func roundTrip(t *testing.T, values ...any) *sql.Rows {
+	t.Helper()
+	fakeDB.rows = nil
+	db, err := sql.Open("gmpfake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, v := range values {
+		if _, err := db.Exec("INSERT", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rows.Close() })
+	return rows
+}
+
+func TestSQLInt(t *testing.T) {
+	x, _ := new(Int).SetString("-123456789012345678901234567890", 10)
+	rows := roundTrip(t, x, int64(42), 7.0, "12.000", nil, SQLInt{}, (*Int)(nil))
+	want := []string{x.String(), "42", "7", "12", "<nil>", "<nil>", "<nil>"}
+	for i := 0; rows.Next(); i++ {
+		var v SQLInt
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		got := "<nil>"
+		if v.Int != nil {
+			got = v.Int.String()
+		}
+		if got != want[i] {
+			t.Errorf("row %d: got %s; want %s", i, got, want[i])
+		}
+	}
+
+	for _, src := range []any{"1.5", 0.5, "x", true} {
+		if err := new(SQLInt).Scan(src); err == nil {
+			t.Errorf("Scan(%v) succeeded", src)
+		}
+	}
+}
+
+func TestSQLRat(t *testing.T) {
+	rows := roundTrip(t, NewRat(-5, 4), "1/3", int64(-2), 0.375, "1.5e-3")
+	want := []string{"-5/4", "1/3", "-2", "3/8", "3/2000"}
+	for i := 0; rows.Next(); i++ {
+		x := new(Rat)
+		if err := rows.Scan(&SQLRat{x}); err != nil {
+			t.Fatal(err)
+		}
+		if got := x.RatString(); got != want[i] {
+			t.Errorf("row %d: got %s; want %s", i, got, want[i])
+		}
+	}
+
+	if v, err := NewRat(-5, 4).Value(); err != nil || v != "-1.25" {
+		t.Errorf("Value(-5/4) = %v, %v; want -1.25", v, err)
+	}
+	if _, err := NewRat(1, 3).Value(); err == nil {
+		t.Error("Value(1/3) succeeded")
+	}
+}
+
+func TestSQLFloat(t *testing.T) {
+	rows := roundTrip(t, NewFloat(-2.5e-3), int64(3), 0.5, "1e3")
+	want := []string{"-0.0025000000000000000520417042793042128323577344417572021484375", "3", "0.5", "1000"}
+	for i := 0; rows.Next(); i++ {
+		var v SQLFloat
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := v.Float.Value()
+		if got != want[i] {
+			t.Errorf("row %d: got %s; want %s", i, got, want[i])
+		}
+	}
+
+	// gmp keeps bits of 1/3 beyond the precision, which must survive.
+	x := NewFloat2(1, 64)
+	x.Div(x, NewFloat2(3, 64))
+	v, _ := x.Value()
+	y := SQLFloat{NewFloat2(0, 64)}
+	if err := y.Scan(v); err != nil || CmpFloat(y.Float, x) != 0 {
+		t.Errorf("Scan(%s) = %s, %v; want %s", v, y.Float, err, x)
+	}
+}
+
+func TestSQLNilValue(t *testing.T) {
+	for _, v := range []driver.Valuer{(*Int)(nil), (*Rat)(nil), (*Float)(nil)} {
+		if got, err := v.Value(); got != nil || err != nil {
+			t.Errorf("%T(nil).Value() = %v, %v; want nil, nil", v, got, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package gmp
+
+import (
+	"io"
+)
+
+// The varint encodings extend those of encoding/binary to integers of any
+// size: an unsigned value is written in groups of 7 bits, least
+// significant first, with the high bit of each byte set if more follow
+// (unsigned LEB128). A signed value n is zigzag encoded first, as 2n if
+// n >= 0 and as -2n-1 if n < 0, so that small negative values stay short.
+// For values that fit into 64 bits the encodings are the same as those of
+// binary.AppendUvarint and binary.AppendVarint.
+
+// appendGroups appends the magnitude mag, in big-endian bytes, to buf in
+// groups of 7 bits.
+func appendGroups(buf []byte, mag []byte) []byte {
+	start := len(buf)
+	var acc, n uint
+	for i := len(mag) - 1; i >= 0; i-- {
+		acc |= uint(mag[i]) << n
+		for n += 8; n >= 7; n -= 7 {
+			buf = append(buf, byte(acc&0x7f))
+			acc >>= 7
+		}
+	}
+	buf = append(buf, byte(acc))
+	for len(buf) > start+1 && buf[len(buf)-1] == 0 {
+		buf = buf[:len(buf)-1]
+	}
+	for i := start; i < len(buf)-1; i++ {
+		buf[i] |= 0x80
+	}
+	return buf
+}
+
+// setGroups sets z to the value of the groups of 7 bits in groups, the
+// last of which has the high bit clear.
+func setGroups(z *Int, groups []byte) *Int {
+	mag := make([]byte, (len(groups)*7+7)/8)
+	i := len(mag)
+	var acc, n uint
+	for _, g := range groups {
+		acc |= uint(g&0x7f) << n
+		for n += 7; n >= 8; n -= 8 {
+			i--
+			mag[i] = byte(acc)
+			acc >>= 8
+		}
+	}
+	if i > 0 {
+		mag[i-1] = byte(acc)
+	}
+	return z.SetBytes(mag)
+}
+
+// zigzag returns the zigzag encoding of x.
+func zigzag(x *Int) *Int {
+	u := new(Int).Lsh(x, 1)
+	if x.Sign() < 0 {
+		u.Neg(u)
+		u.Sub(u, intOne)
+	}
+	return u
+}
+
+// unzigzag sets z to the value with the zigzag encoding z.
+func unzigzag(z *Int) {
+	if z.Bit(0) == 0 {
+		z.Rsh(z, 1)
+		return
+	}
+	z.Add(z, intOne)
+	z.Rsh(z, 1)
+	z.Neg(z)
+}
+
+// uvarintLen returns the length of the varint at the start of buf, or 0
+// if buf ends before it does.
+func uvarintLen(buf []byte) int {
+	for i, b := range buf {
+		if b < 0x80 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// readGroups reads the groups of a varint from r.
+func readGroups(r io.ByteReader) ([]byte, error) {
+	var groups []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(groups) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		groups = append(groups, b)
+		if b < 0x80 {
+			return groups, nil
+		}
+	}
+}
+
+// AppendUvarint appends the unsigned varint encoding of x to buf and
+// returns the extended buffer. AppendUvarint panics if x is negative.
+func (x *Int) AppendUvarint(buf []byte) []byte {
+	if x.Sign() < 0 {
+		panic("gmp: Int.AppendUvarint: negative value")
+	}
+	return appendGroups(buf, x.Bytes())
+}
+
+// AppendVarint appends the signed, zigzag varint encoding of x to buf and
+// returns the extended buffer.
+func (x *Int) AppendVarint(buf []byte) []byte {
+	u := zigzag(x)
+	defer u.Clear()
+	return appendGroups(buf, u.Bytes())
+}
+
+// SetUvarint sets z to the unsigned varint at the start of buf, and
+// returns z and the number of bytes read. If buf ends before the varint,
+// SetUvarint returns nil and 0.
+func (z *Int) SetUvarint(buf []byte) (*Int, int) {
+	n := uvarintLen(buf)
+	if n == 0 {
+		return nil, 0
+	}
+	return setGroups(z, buf[:n]), n
+}
+
+// SetVarint sets z to the signed, zigzag varint at the start of buf, and
+// returns z and the number of bytes read. If buf ends before the varint,
+// SetVarint returns nil and 0.
+func (z *Int) SetVarint(buf []byte) (*Int, int) {
+	n := uvarintLen(buf)
+	if n == 0 {
+		return nil, 0
+	}
+	unzigzag(setGroups(z, buf[:n]))
+	return z, n
+}
+
+// ReadUvarint sets z to an unsigned varint read from r. The error is
+// io.EOF only if no bytes were read, and io.ErrUnexpectedEOF if r ends
+// within the varint.
+func (z *Int) ReadUvarint(r io.ByteReader) error {
+	groups, err := readGroups(r)
+	if err != nil {
+		return err
+	}
+	setGroups(z, groups)
+	return nil
+}
+
+// ReadVarint sets z to a signed, zigzag varint read from r. The error is
+// io.EOF only if no bytes were read, and io.ErrUnexpectedEOF if r ends
+// within the varint.
+func (z *Int) ReadVarint(r io.ByteReader) error {
+	groups, err := readGroups(r)
+	if err != nil {
+		return err
+	}
+	unzigzag(setGroups(z, groups))
+	return nil
+}